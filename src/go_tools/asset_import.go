@@ -0,0 +1,515 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+/**
+ * asset_import - распаковка архивов ассетов (.zip, .tar.gz), аналог
+ * импортёра пакетов ассетов в Godot. AssetManager.importAsset раньше умел
+ * копировать только один файл за раз; теперь он же распознаёт архив по
+ * расширению, показывает дерево содержимого для подтверждения (с
+ * возможностью снять файлы и задать подпапку назначения), проверяет пути
+ * на traversal и пишет assets/manifest.json с SHA-256 каждого файла, чтобы
+ * следующий импорт мог предложить overwrite/skip/rename для дублей.
+ */
+
+// manifestFileName - имя файла манифеста внутри AssetManager.assetPath.
+const manifestFileName = "manifest.json"
+
+// AssetManifestEntry описывает один импортированный файл ассетов.
+type AssetManifestEntry struct {
+	SourceArchive string    `json:"source_archive"`
+	SHA256        string    `json:"sha256"`
+	Size          int64     `json:"size"`
+	ImportedAt    time.Time `json:"imported_at"`
+}
+
+// AssetManifest - записи манифеста, ключ - путь ассета относительно assetPath.
+type AssetManifest map[string]AssetManifestEntry
+
+// loadAssetManifest читает assets/manifest.json, либо возвращает пустой
+// манифест, если файла ещё нет (первый импорт в эту директорию).
+func loadAssetManifest(assetPath string) (AssetManifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(assetPath, manifestFileName))
+	if os.IsNotExist(err) {
+		return AssetManifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset manifest: %w", err)
+	}
+
+	var manifest AssetManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse asset manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// saveAssetManifest пишет манифест обратно в assets/manifest.json.
+func saveAssetManifest(assetPath string, manifest AssetManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode asset manifest: %w", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(assetPath, manifestFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write asset manifest: %w", err)
+	}
+	return nil
+}
+
+// archiveEntry - один файл внутри распаковываемого архива, плюс решение
+// пользователя (включать ли его в импорт).
+type archiveEntry struct {
+	path    string
+	size    int64
+	data    []byte
+	include *bool
+}
+
+// importProgress - отчёт о ходе распаковки, отправляемый в канал прогресса.
+type importProgress struct {
+	FilesDone  int
+	FilesTotal int
+	BytesDone  int64
+	BytesTotal int64
+	Err        error
+	Done       bool
+}
+
+// importConflictPolicy - как поступать с файлом, чей путь назначения уже
+// есть в манифесте.
+type importConflictPolicy string
+
+const (
+	importOverwrite importConflictPolicy = "overwrite"
+	importSkip      importConflictPolicy = "skip"
+	importRename    importConflictPolicy = "rename"
+)
+
+// resolveImportDest применяет policy к destPath, зная, что он уже числится
+// в manifest. Возвращает итоговый путь (может отличаться при rename) и
+// proceed=false, если файл следует пропустить.
+func resolveImportDest(manifest AssetManifest, destPath string, policy importConflictPolicy) (resolved string, proceed bool) {
+	if _, exists := manifest[destPath]; !exists {
+		return destPath, true
+	}
+
+	switch policy {
+	case importOverwrite:
+		return destPath, true
+	case importRename:
+		return uniqueDestPath(manifest, destPath), true
+	default: // importSkip
+		return "", false
+	}
+}
+
+// uniqueDestPath находит первый путь вида "name (1).ext", "name (2).ext",
+// ... ещё не занятый в manifest.
+func uniqueDestPath(manifest AssetManifest, destPath string) string {
+	ext := filepath.Ext(destPath)
+	base := strings.TrimSuffix(destPath, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, exists := manifest[candidate]; !exists {
+			return candidate
+		}
+	}
+}
+
+// isArchiveName сообщает, следует ли filename распаковывать как архив, а не
+// копировать как одиночный ассет.
+func isArchiveName(filename string) bool {
+	lower := strings.ToLower(filename)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// importAsset импортирует файл, выбранный в диалоге: архивы (.zip,
+// .tar.gz/.tgz) распаковываются через importArchive, всё остальное
+// копируется как одиночный ассет, как и раньше - но теперь тоже попадает
+// в манифест.
+func (am *AssetManager) importAsset() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, am.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		filename := filepath.Base(reader.URI().String())
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(err, am.window)
+			return
+		}
+
+		if _, err := os.Stat(am.assetPath); os.IsNotExist(err) {
+			os.MkdirAll(am.assetPath, 0755)
+		}
+
+		if isArchiveName(filename) {
+			am.importArchive(filename, data)
+			return
+		}
+
+		manifest, err := loadAssetManifest(am.assetPath)
+		if err != nil {
+			dialog.ShowError(err, am.window)
+			return
+		}
+
+		if _, exists := manifest[filename]; exists {
+			am.showImportConflictDialog(filename, func(policy importConflictPolicy) {
+				destPath, proceed := resolveImportDest(manifest, filename, policy)
+				if !proceed {
+					return
+				}
+				am.finishSingleFileImport(destPath, filename, data)
+			})
+			return
+		}
+
+		am.finishSingleFileImport(filename, filename, data)
+	}, am.window)
+}
+
+// finishSingleFileImport writes data to destPath via importSingleFile and
+// reports success or failure to the user.
+func (am *AssetManager) finishSingleFileImport(destPath, sourceArchive string, data []byte) {
+	if err := am.importSingleFile(destPath, sourceArchive, data); err != nil {
+		dialog.ShowError(err, am.window)
+		return
+	}
+
+	am.assetList.Refresh()
+	dialog.ShowInformation("Success", fmt.Sprintf("Asset imported as %s", destPath), am.window)
+}
+
+// showImportConflictDialog asks the user how to handle a file whose
+// destination path already has a manifest entry: overwrite it in place,
+// skip the import, or import it alongside the existing one under a
+// renamed path. onChoice is called with the user's decision.
+func (am *AssetManager) showImportConflictDialog(destPath string, onChoice func(importConflictPolicy)) {
+	message := widget.NewLabel(fmt.Sprintf("%s was already imported.\nOverwrite, skip, or import under a new name?", destPath))
+
+	var d dialog.Dialog
+	buttons := container.NewHBox(
+		widget.NewButton("Overwrite", func() {
+			d.Hide()
+			onChoice(importOverwrite)
+		}),
+		widget.NewButton("Rename", func() {
+			d.Hide()
+			onChoice(importRename)
+		}),
+		widget.NewButton("Skip", func() {
+			d.Hide()
+			onChoice(importSkip)
+		}),
+	)
+
+	d = dialog.NewCustomWithoutButtons("Duplicate Asset", container.NewVBox(message, buttons), am.window)
+	d.Show()
+}
+
+// importSingleFile копирует data в assetPath/destPath и записывает
+// соответствующую запись в манифест (sourceArchive - имя исходного файла,
+// выбранного в диалоге импорта).
+func (am *AssetManager) importSingleFile(destPath, sourceArchive string, data []byte) error {
+	fullPath := filepath.Join(am.assetPath, destPath)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	if err := ioutil.WriteFile(fullPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", destPath, err)
+	}
+
+	manifest, err := loadAssetManifest(am.assetPath)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	manifest[destPath] = AssetManifestEntry{
+		SourceArchive: sourceArchive,
+		SHA256:        hex.EncodeToString(sum[:]),
+		Size:          int64(len(data)),
+		ImportedAt:    time.Now(),
+	}
+	return saveAssetManifest(am.assetPath, manifest)
+}
+
+// importArchive читает содержимое zip/tar.gz архива в память, строит
+// дерево файлов и показывает диалог подтверждения перед распаковкой.
+func (am *AssetManager) importArchive(filename string, data []byte) {
+	entries, err := listArchiveEntries(filename, data)
+	if err != nil {
+		dialog.ShowError(err, am.window)
+		return
+	}
+	if len(entries) == 0 {
+		dialog.ShowInformation("Empty archive", "No files found in archive", am.window)
+		return
+	}
+
+	am.showArchiveConfirmation(filename, entries)
+}
+
+// listArchiveEntries распаковывает архив в память и возвращает его записи,
+// отклоняя любой путь, содержащий ".." (защита от zip/tar-slip traversal).
+func listArchiveEntries(filename string, data []byte) ([]*archiveEntry, error) {
+	lower := strings.ToLower(filename)
+	if strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") {
+		return listTarGzEntries(data)
+	}
+	return listZipEntries(data)
+}
+
+func listZipEntries(data []byte) ([]*archiveEntry, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+
+	var entries []*archiveEntry
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := validateArchivePath(f.Name); err != nil {
+			return nil, err
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", f.Name, err)
+		}
+		content, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", f.Name, err)
+		}
+
+		include := true
+		entries = append(entries, &archiveEntry{path: f.Name, size: int64(len(content)), data: content, include: &include})
+	}
+	return entries, nil
+}
+
+func listTarGzEntries(data []byte) ([]*archiveEntry, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tar.gz archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var entries []*archiveEntry
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar.gz archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := validateArchivePath(header.Name); err != nil {
+			return nil, err
+		}
+
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", header.Name, err)
+		}
+
+		include := true
+		entries = append(entries, &archiveEntry{path: header.Name, size: int64(len(content)), data: content, include: &include})
+	}
+	return entries, nil
+}
+
+// validateArchivePath rejects absolute paths and ".." traversal entries, so
+// a crafted archive can't write outside the chosen destination subdirectory.
+func validateArchivePath(name string) error {
+	clean := filepath.Clean(name)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || strings.Contains(clean, string(filepath.Separator)+"..") {
+		return fmt.Errorf("archive entry %q attempts path traversal", name)
+	}
+	return nil
+}
+
+// showArchiveConfirmation displays the archive's file tree with a checkbox
+// per file plus a destination-subdirectory entry, and starts extraction
+// once the user confirms.
+func (am *AssetManager) showArchiveConfirmation(filename string, entries []*archiveEntry) {
+	destEntry := widget.NewEntry()
+	destEntry.SetPlaceHolder("Destination subdirectory (optional)")
+
+	policyRadio := widget.NewRadioGroup([]string{"Skip", "Overwrite", "Rename"}, nil)
+	policyRadio.Horizontal = true
+	policyRadio.SetSelected("Skip")
+
+	var checks []fyne.CanvasObject
+	for _, entry := range entries {
+		entry := entry
+		check := widget.NewCheck(fmt.Sprintf("%s (%d bytes)", entry.path, entry.size), func(checked bool) {
+			*entry.include = checked
+		})
+		check.SetChecked(true)
+		checks = append(checks, check)
+	}
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("%s contains %d file(s):", filename, len(entries))),
+		destEntry,
+		container.NewVScroll(container.NewVBox(checks...)),
+		widget.NewLabel("If a file was already imported before:"),
+		policyRadio,
+	)
+
+	confirm := dialog.NewCustomConfirm("Import Archive", "Import", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		var selected []*archiveEntry
+		for _, entry := range entries {
+			if *entry.include {
+				selected = append(selected, entry)
+			}
+		}
+		policy := importConflictPolicyFromLabel(policyRadio.Selected)
+		am.extractArchiveWithProgress(filename, destEntry.Text, selected, policy)
+	}, am.window)
+	confirm.Resize(fyne.NewSize(500, 400))
+	confirm.Show()
+}
+
+// importConflictPolicyFromLabel maps the archive-confirmation dialog's
+// radio button labels onto importConflictPolicy, defaulting to importSkip
+// for an unrecognized or empty selection.
+func importConflictPolicyFromLabel(label string) importConflictPolicy {
+	switch label {
+	case "Overwrite":
+		return importOverwrite
+	case "Rename":
+		return importRename
+	default:
+		return importSkip
+	}
+}
+
+// extractArchiveWithProgress writes the selected entries to disk on a
+// background goroutine, reporting progress over a channel that a
+// dialog.ProgressDialog on the UI thread reads from. Entries whose
+// destination path is already in the manifest are resolved against policy
+// (overwrite in place, skip entirely, or write alongside the existing file
+// under a renamed path).
+func (am *AssetManager) extractArchiveWithProgress(archiveName, destSubdir string, entries []*archiveEntry, policy importConflictPolicy) {
+	var totalBytes int64
+	for _, entry := range entries {
+		totalBytes += entry.size
+	}
+
+	progressCh := make(chan importProgress, len(entries)+1)
+	progressDialog := dialog.NewProgress("Importing Assets", "Extracting "+archiveName+"...", am.window)
+	progressDialog.Show()
+
+	go func() {
+		manifest, err := loadAssetManifest(am.assetPath)
+		if err != nil {
+			progressCh <- importProgress{Err: err, Done: true}
+			return
+		}
+
+		var bytesDone int64
+		for i, entry := range entries {
+			destPath := entry.path
+			if destSubdir != "" {
+				destPath = filepath.Join(destSubdir, entry.path)
+			}
+
+			resolvedPath, proceed := resolveImportDest(manifest, destPath, policy)
+			if !proceed {
+				bytesDone += entry.size
+				progressCh <- importProgress{FilesDone: i + 1, FilesTotal: len(entries), BytesDone: bytesDone, BytesTotal: totalBytes}
+				continue
+			}
+			destPath = resolvedPath
+
+			fullPath := filepath.Join(am.assetPath, destPath)
+			if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+				progressCh <- importProgress{Err: fmt.Errorf("failed to create directory for %s: %w", destPath, err), Done: true}
+				return
+			}
+			if err := ioutil.WriteFile(fullPath, entry.data, 0644); err != nil {
+				progressCh <- importProgress{Err: fmt.Errorf("failed to write %s: %w", destPath, err), Done: true}
+				return
+			}
+
+			sum := sha256.Sum256(entry.data)
+			manifest[destPath] = AssetManifestEntry{
+				SourceArchive: archiveName,
+				SHA256:        hex.EncodeToString(sum[:]),
+				Size:          entry.size,
+				ImportedAt:    time.Now(),
+			}
+
+			bytesDone += entry.size
+			progressCh <- importProgress{FilesDone: i + 1, FilesTotal: len(entries), BytesDone: bytesDone, BytesTotal: totalBytes}
+		}
+
+		if err := saveAssetManifest(am.assetPath, manifest); err != nil {
+			progressCh <- importProgress{Err: err, Done: true}
+			return
+		}
+
+		progressCh <- importProgress{FilesDone: len(entries), FilesTotal: len(entries), BytesDone: totalBytes, BytesTotal: totalBytes, Done: true}
+	}()
+
+	go func() {
+		for p := range progressCh {
+			if p.FilesTotal > 0 {
+				progressDialog.SetValue(float64(p.FilesDone) / float64(p.FilesTotal))
+			}
+
+			if p.Err != nil {
+				progressDialog.Hide()
+				dialog.ShowError(p.Err, am.window)
+				return
+			}
+
+			if p.Done {
+				progressDialog.Hide()
+				am.assetList.Refresh()
+				dialog.ShowInformation("Success", fmt.Sprintf("Imported %d file(s) from %s", p.FilesTotal, archiveName), am.window)
+				return
+			}
+		}
+	}()
+}
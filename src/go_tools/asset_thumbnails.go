@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+/**
+ * asset_thumbnails - кэш превью для AssetManager. Раньше loadAssetPreview
+ * показывал только PNG/JPG/GIF как есть и заглушку для всего остального;
+ * теперь при сканировании директории пул воркеров (по одному на ядро)
+ * генерирует JPEG-превью в assetPath/.thumbs/<sha256>.jpg для изображений
+ * (ресайз через x/image/draw), видео (первый кадр через ffmpeg) и аудио
+ * (грубая waveform-картинка), а список ассетов и предпросмотр текстовых
+ * файлов получают соответствующее обновление.
+ */
+
+const thumbsDirName = ".thumbs"
+const thumbIndexFileName = "index.json"
+const thumbSize = 128
+
+// thumbCacheEntry - запись sidecar-индекса .thumbs/index.json: позволяет
+// решить, устарела ли уже сгенерированная миниатюра, без перечитывания и
+// повторного хеширования содержимого файла при каждом сканировании.
+type thumbCacheEntry struct {
+	ModTime int64  `json:"mtime"`
+	Size    int64  `json:"size"`
+	Thumb   string `json:"thumb"`
+}
+
+// thumbIndex - sidecar-индекс, ключ - путь ассета относительно assetPath.
+type thumbIndex map[string]thumbCacheEntry
+
+func (am *AssetManager) thumbsDir() string {
+	return filepath.Join(am.assetPath, thumbsDirName)
+}
+
+func (am *AssetManager) loadThumbIndex() thumbIndex {
+	data, err := ioutil.ReadFile(filepath.Join(am.thumbsDir(), thumbIndexFileName))
+	if err != nil {
+		return thumbIndex{}
+	}
+	var idx thumbIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return thumbIndex{}
+	}
+	return idx
+}
+
+func (am *AssetManager) saveThumbIndex(idx thumbIndex) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnail index: %w", err)
+	}
+	return ioutil.WriteFile(filepath.Join(am.thumbsDir(), thumbIndexFileName), data, 0644)
+}
+
+// thumbPathFor returns the stable cache filename for relPath - keyed by the
+// hash of the asset's path (not its content), since invalidation is decided
+// separately from mtime+size in the sidecar index.
+func thumbPathFor(thumbsDir, relPath string) string {
+	sum := sha256.Sum256([]byte(relPath))
+	return filepath.Join(thumbsDir, hex.EncodeToString(sum[:])+".jpg")
+}
+
+// generateThumbnails scans the asset directory and (re)builds any missing or
+// stale thumbnails using a worker pool sized to runtime.NumCPU(), then
+// refreshes the asset list so the cached icons become visible.
+func (am *AssetManager) generateThumbnails() {
+	if err := os.MkdirAll(am.thumbsDir(), 0755); err != nil {
+		return
+	}
+
+	files := am.getAssetFiles()
+	oldIndex := am.loadThumbIndex()
+	newIndex := make(thumbIndex, len(files))
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	workers := runtime.NumCPU()
+	if workers < 1 {
+		workers = 1
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for relPath := range jobs {
+				entry, thumbPath, ok := am.buildThumbnail(relPath, oldIndex[relPath])
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				newIndex[relPath] = entry
+				am.thumbMu.Lock()
+				am.thumbs[relPath] = thumbPath
+				am.thumbMu.Unlock()
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, relPath := range files {
+		jobs <- relPath
+	}
+	close(jobs)
+	wg.Wait()
+
+	am.saveThumbIndex(newIndex)
+	if am.assetList != nil {
+		am.assetList.Refresh()
+	}
+}
+
+// buildThumbnail generates (or reuses, if mtime+size are unchanged) the
+// cached thumbnail for relPath. Returns ok=false for asset types with no
+// thumbnail representation (plain text, unrecognized extensions, ...).
+func (am *AssetManager) buildThumbnail(relPath string, cached thumbCacheEntry) (thumbCacheEntry, string, bool) {
+	// На диске - обычный пользовательский/shadow-файл с настоящим mtime; для
+	// чисто embedded-ассета (ещё не импортированного поверх) mtime всегда 0 -
+	// его содержимое зашито в бинарник на момент сборки и не меняется.
+	var modTime int64
+	diskPath := filepath.Join(am.assetPath, relPath)
+	if info, err := os.Stat(diskPath); err == nil {
+		modTime = info.ModTime().Unix()
+	}
+
+	data, err := am.readAssetBytes(relPath)
+	if err != nil {
+		return thumbCacheEntry{}, "", false
+	}
+
+	thumbPath := thumbPathFor(am.thumbsDir(), relPath)
+	if cached.ModTime == modTime && cached.Size == int64(len(data)) {
+		if _, err := os.Stat(thumbPath); err == nil {
+			return cached, thumbPath, true
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(relPath))
+	var genErr error
+	switch {
+	case ext == ".png" || ext == ".jpg" || ext == ".jpeg" || ext == ".gif":
+		genErr = generateImageThumbnail(data, thumbPath)
+	case videoPreviewExtensions[ext]:
+		genErr = generateVideoThumbnail(data, thumbPath)
+	case audioPreviewExtensions[ext]:
+		genErr = generateWaveformThumbnail(data, thumbPath)
+	default:
+		return thumbCacheEntry{}, "", false
+	}
+	if genErr != nil {
+		return thumbCacheEntry{}, "", false
+	}
+
+	entry := thumbCacheEntry{ModTime: modTime, Size: int64(len(data)), Thumb: filepath.Base(thumbPath)}
+	return entry, thumbPath, true
+}
+
+// generateImageThumbnail decodes data and writes a thumbSize x thumbSize
+// JPEG thumbnail to dstPath, resizing with Catmull-Rom interpolation.
+func generateImageThumbnail(data []byte, dstPath string) error {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, thumbSize, thumbSize))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	return writeJPEGThumbnail(dstPath, dst)
+}
+
+// generateVideoThumbnail shells out to ffmpeg to extract the first frame as a
+// JPEG at dstPath, scaled to thumbSize pixels wide with the height following
+// the aspect ratio. ffmpeg needs a
+// real file, so data is spilled to a temp file first (the source may only
+// exist in the embedded defaults FS, with no file on disk).
+func generateVideoThumbnail(data []byte, dstPath string) error {
+	tmp, err := ioutil.TempFile("", "asset-thumb-src-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	cmd := exec.Command("ffmpeg", "-y", "-i", tmp.Name(), "-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:-1", thumbSize), dstPath)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg first-frame extraction failed: %w", err)
+	}
+	return nil
+}
+
+// generateWaveformThumbnail renders a crude amplitude-over-time waveform, as
+// a JPEG thumbnail like the others, for an audio file by treating its raw bytes as a proxy signal - there's no
+// real audio decoder in this package, so this approximates relative loudness
+// rather than decoding actual PCM samples.
+func generateWaveformThumbnail(data []byte, dstPath string) error {
+	img := image.NewRGBA(image.Rect(0, 0, thumbSize, thumbSize))
+	for y := 0; y < thumbSize; y++ {
+		for x := 0; x < thumbSize; x++ {
+			img.Set(x, y, color.RGBA{30, 30, 30, 255})
+		}
+	}
+
+	if len(data) == 0 {
+		return writeJPEGThumbnail(dstPath, img)
+	}
+
+	samplesPerColumn := len(data) / thumbSize
+	if samplesPerColumn < 1 {
+		samplesPerColumn = 1
+	}
+
+	mid := thumbSize / 2
+	for x := 0; x < thumbSize; x++ {
+		start := x * samplesPerColumn
+		if start >= len(data) {
+			break
+		}
+		end := start + samplesPerColumn
+		if end > len(data) {
+			end = len(data)
+		}
+
+		amplitude := averageAmplitude(data[start:end])
+		barHeight := int(amplitude * float64(mid))
+
+		for dy := -barHeight; dy <= barHeight; dy++ {
+			y := mid + dy
+			if y >= 0 && y < thumbSize {
+				img.Set(x, y, color.RGBA{80, 200, 120, 255})
+			}
+		}
+	}
+
+	return writeJPEGThumbnail(dstPath, img)
+}
+
+// averageAmplitude maps a byte window to a [0, 1] amplitude, centering the
+// raw byte values around zero the way signed 8-bit PCM would be.
+func averageAmplitude(window []byte) float64 {
+	var sum float64
+	for _, b := range window {
+		sum += math.Abs(float64(int(b) - 128))
+	}
+	return (sum / float64(len(window))) / 128.0
+}
+
+func writeJPEGThumbnail(dstPath string, img image.Image) error {
+	out, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return jpeg.Encode(out, img, &jpeg.Options{Quality: 85})
+}
+
+// --- Syntax-highlighted text preview -------------------------------------
+
+var (
+	luaKeywords = map[string]bool{
+		"function": true, "local": true, "end": true, "if": true, "then": true,
+		"else": true, "elseif": true, "return": true, "nil": true, "true": true,
+		"false": true, "for": true, "while": true, "do": true, "and": true, "or": true, "not": true,
+	}
+
+	stringLiteralRe = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	numberLiteralRe = regexp.MustCompile(`\b-?\d+(\.\d+)?\b`)
+	luaCommentRe    = regexp.MustCompile(`--.*`)
+	wordRe          = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// highlightSegments tokenizes text into a small set of RichText segments:
+// strings, numbers, comments (Lua only) and keywords (Lua only) get distinct
+// colors, everything else renders as plain foreground text. This is a
+// lightweight approximation of real syntax highlighting, proportional to
+// what a DevTools preview pane needs rather than a full tokenizer/grammar.
+func highlightSegments(text, ext string) []widget.RichTextSegment {
+	type span = struct {
+		start, end int
+		style      widget.RichTextStyle
+	}
+
+	var spans []span
+	addMatches := func(re *regexp.Regexp, style widget.RichTextStyle) {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			spans = append(spans, span{loc[0], loc[1], style})
+		}
+	}
+
+	stringStyle := widget.RichTextStyle{ColorName: theme.ColorNameSuccess}
+	numberStyle := widget.RichTextStyle{ColorName: theme.ColorNamePrimary}
+	commentStyle := widget.RichTextStyle{ColorName: theme.ColorNameDisabled}
+	keywordStyle := widget.RichTextStyle{ColorName: theme.ColorNameWarning}
+
+	addMatches(stringLiteralRe, stringStyle)
+	addMatches(numberLiteralRe, numberStyle)
+	if ext == ".lua" {
+		addMatches(luaCommentRe, commentStyle)
+		for _, loc := range wordRe.FindAllStringIndex(text, -1) {
+			if luaKeywords[text[loc[0]:loc[1]]] {
+				spans = append(spans, span{loc[0], loc[1], keywordStyle})
+			}
+		}
+	}
+
+	if len(spans) == 0 {
+		return []widget.RichTextSegment{&widget.TextSegment{Text: text, Style: widget.RichTextStyle{ColorName: theme.ColorNameForeground}}}
+	}
+
+	// Sort spans by start (spans with the same start keep the order they were
+	// added in: strings, numbers, comments, keywords) and keep each span that
+	// doesn't overlap one already kept, so overlapping matches (e.g. a number
+	// inside a comment) don't double up.
+	sortSpansByStart(spans)
+	var kept []span
+	cursor := 0
+	for _, s := range spans {
+		if s.start < cursor {
+			continue
+		}
+		kept = append(kept, s)
+		cursor = s.end
+	}
+
+	var segments []widget.RichTextSegment
+	pos := 0
+	for _, s := range kept {
+		if s.start > pos {
+			segments = append(segments, &widget.TextSegment{Text: text[pos:s.start], Style: widget.RichTextStyle{ColorName: theme.ColorNameForeground}})
+		}
+		segments = append(segments, &widget.TextSegment{Text: text[s.start:s.end], Style: s.style})
+		pos = s.end
+	}
+	if pos < len(text) {
+		segments = append(segments, &widget.TextSegment{Text: text[pos:], Style: widget.RichTextStyle{ColorName: theme.ColorNameForeground}})
+	}
+
+	return segments
+}
+
+func sortSpansByStart(spans []struct {
+	start, end int
+	style      widget.RichTextStyle
+}) {
+	for i := 1; i < len(spans); i++ {
+		for j := i; j > 0 && spans[j-1].start > spans[j].start; j-- {
+			spans[j-1], spans[j] = spans[j], spans[j-1]
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+/**
+ * asset_watcher - live-reload for a running game instance. AssetWatcher wraps
+ * fsnotify to recursively watch AssetManager.assetPath and publish
+ * create/modify/delete events on a typed channel, debounced (200ms) and
+ * deduplicated by content hash so one save doesn't fan out into a burst of
+ * reloads. assetRPCServer relays the same events as JSON-RPC notifications
+ * over a TCP (or Unix domain) socket, so a running SuperTetris process can
+ * subscribe and reload textures, sounds and settings without a restart.
+ */
+
+// assetWatchDebounce is the quiet period after the last write to a path
+// before its event is resolved and published.
+const assetWatchDebounce = 200 * time.Millisecond
+
+// defaultAssetReloadPort is the TCP port the live-reload JSON-RPC server
+// listens on unless the caller overrides it.
+const defaultAssetReloadPort = 4711
+
+// AssetEventKind is the kind of change an AssetEvent reports.
+type AssetEventKind string
+
+const (
+	AssetEventCreated  AssetEventKind = "created"
+	AssetEventModified AssetEventKind = "modified"
+	AssetEventDeleted  AssetEventKind = "deleted"
+)
+
+// AssetEvent is one change published by AssetWatcher, keyed by path relative
+// to AssetManager.assetPath. Hash is empty for AssetEventDeleted.
+type AssetEvent struct {
+	Path string         `json:"path"`
+	Kind AssetEventKind `json:"kind"`
+	Hash string         `json:"hash"`
+}
+
+// AssetWatcher recursively observes an asset directory with fsnotify and
+// publishes debounced, hash-deduplicated AssetEvents to any number of
+// subscribers plus an optional assetRPCServer.
+type AssetWatcher struct {
+	assetPath string
+	fsWatcher *fsnotify.Watcher
+	rpcServer *assetRPCServer
+
+	// onEvent, if set, is called synchronously with every published event -
+	// AssetManager uses it to drive the DevTools reload log.
+	onEvent func(AssetEvent)
+
+	mu          sync.Mutex
+	subscribers []chan AssetEvent
+	pending     map[string]*time.Timer
+	lastHash    map[string]string
+}
+
+// newAssetWatcher creates a watcher rooted at assetPath and adds a recursive
+// fsnotify watch over its existing subdirectories. Call Start to begin
+// publishing events.
+func newAssetWatcher(assetPath string) (*AssetWatcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+
+	w := &AssetWatcher{
+		assetPath: assetPath,
+		fsWatcher: fsWatcher,
+		pending:   make(map[string]*time.Timer),
+		lastHash:  make(map[string]string),
+	}
+
+	if err := w.addRecursive(assetPath); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// addRecursive registers an fsnotify watch on root and every directory below
+// it - fsnotify itself only watches a single directory, not a tree.
+func (w *AssetWatcher) addRecursive(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if err := w.fsWatcher.Add(path); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Start runs the fsnotify event loop on a background goroutine until Stop
+// closes the underlying watcher.
+func (w *AssetWatcher) Start() {
+	go func() {
+		for {
+			select {
+			case event, ok := <-w.fsWatcher.Events:
+				if !ok {
+					return
+				}
+				w.handleFsEvent(event)
+			case err, ok := <-w.fsWatcher.Errors:
+				if !ok {
+					return
+				}
+				appLogger.Warn("asset watcher error", "path", w.assetPath, "error", err)
+			}
+		}
+	}()
+}
+
+// Stop closes the underlying fsnotify watcher, ending the event loop started
+// by Start.
+func (w *AssetWatcher) Stop() error {
+	return w.fsWatcher.Close()
+}
+
+// handleFsEvent resets the debounce timer for the changed path. A newly
+// created directory is watched immediately, rather than waiting out its own
+// debounce window, so files written into it right away aren't missed.
+func (w *AssetWatcher) handleFsEvent(event fsnotify.Event) {
+	if event.Op&fsnotify.Create != 0 {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.fsWatcher.Add(event.Name)
+			return
+		}
+	}
+
+	relPath, err := filepath.Rel(w.assetPath, event.Name)
+	if err != nil {
+		relPath = event.Name
+	}
+
+	w.mu.Lock()
+	if timer, ok := w.pending[relPath]; ok {
+		timer.Stop()
+	}
+	w.pending[relPath] = time.AfterFunc(assetWatchDebounce, func() {
+		w.resolveAndPublish(relPath, event.Name)
+	})
+	w.mu.Unlock()
+}
+
+// resolveAndPublish runs once relPath's debounce window has elapsed: it
+// re-reads the file to decide created/modified/deleted, drops the event if
+// the content hash hasn't actually changed since the last publish, and
+// otherwise publishes it.
+func (w *AssetWatcher) resolveAndPublish(relPath, fullPath string) {
+	w.mu.Lock()
+	delete(w.pending, relPath)
+	w.mu.Unlock()
+
+	data, err := ioutil.ReadFile(fullPath)
+
+	w.mu.Lock()
+	previousHash, seen := w.lastHash[relPath]
+
+	var event AssetEvent
+	if err != nil {
+		if !seen {
+			w.mu.Unlock()
+			return // never published for this path, deletion is a no-op
+		}
+		delete(w.lastHash, relPath)
+		event = AssetEvent{Path: relPath, Kind: AssetEventDeleted}
+	} else {
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		if seen && previousHash == hash {
+			w.mu.Unlock()
+			return // content unchanged - not a real event (e.g. touch)
+		}
+
+		kind := AssetEventModified
+		if !seen {
+			kind = AssetEventCreated
+		}
+		w.lastHash[relPath] = hash
+		event = AssetEvent{Path: relPath, Kind: kind, Hash: hash}
+	}
+	w.mu.Unlock()
+
+	w.publish(event)
+}
+
+// Subscribe returns a channel that receives every AssetEvent published from
+// now on. The channel is buffered; a subscriber that falls behind has events
+// silently dropped rather than stalling the watcher.
+func (w *AssetWatcher) Subscribe() <-chan AssetEvent {
+	ch := make(chan AssetEvent, 32)
+	w.mu.Lock()
+	w.subscribers = append(w.subscribers, ch)
+	w.mu.Unlock()
+	return ch
+}
+
+func (w *AssetWatcher) publish(event AssetEvent) {
+	w.mu.Lock()
+	subs := append([]chan AssetEvent(nil), w.subscribers...)
+	w.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+
+	if w.rpcServer != nil {
+		w.rpcServer.broadcast(event)
+	}
+	if w.onEvent != nil {
+		w.onEvent(event)
+	}
+}
+
+// --- JSON-RPC push server -------------------------------------------------
+
+// assetReloadNotification is the JSON-RPC 2.0 notification (no id, no
+// response expected) pushed to every connected client on each AssetEvent.
+type assetReloadNotification struct {
+	JSONRPC string     `json:"jsonrpc"`
+	Method  string     `json:"method"`
+	Params  AssetEvent `json:"params"`
+}
+
+// assetRPCServer accepts connections from running game instances and pushes
+// an "asset.reload" notification, newline-delimited JSON, to each one as
+// AssetWatcher publishes events.
+type assetRPCServer struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+
+	// onClientsChanged, if set, is called with the new connected-client
+	// count whenever a client connects or disconnects.
+	onClientsChanged func(count int)
+}
+
+// startAssetRPCServer listens on addr and starts accepting client
+// connections in the background. addr is a TCP address (e.g. ":4711") unless
+// prefixed with "unix://", in which case it names a Unix domain socket path.
+func startAssetRPCServer(addr string) (*assetRPCServer, error) {
+	network, address := "tcp", addr
+	if strings.HasPrefix(addr, "unix://") {
+		network, address = "unix", strings.TrimPrefix(addr, "unix://")
+		os.Remove(address) // сокет мог остаться от не до конца завершившегося прошлого запуска
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start asset reload server on %s: %w", addr, err)
+	}
+
+	s := &assetRPCServer{listener: listener, clients: make(map[net.Conn]struct{})}
+	go s.acceptLoop()
+	return s, nil
+}
+
+func (s *assetRPCServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return // listener closed
+		}
+		s.addClient(conn)
+	}
+}
+
+func (s *assetRPCServer) addClient(conn net.Conn) {
+	s.mu.Lock()
+	s.clients[conn] = struct{}{}
+	count := len(s.clients)
+	s.mu.Unlock()
+
+	if s.onClientsChanged != nil {
+		s.onClientsChanged(count)
+	}
+
+	// Клиент только получает уведомления; читаем из соединения лишь чтобы
+	// заметить, когда он отключится.
+	go func() {
+		reader := bufio.NewReader(conn)
+		for {
+			if _, err := reader.ReadByte(); err != nil {
+				s.removeClient(conn)
+				return
+			}
+		}
+	}()
+}
+
+func (s *assetRPCServer) removeClient(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.clients, conn)
+	count := len(s.clients)
+	s.mu.Unlock()
+	conn.Close()
+
+	if s.onClientsChanged != nil {
+		s.onClientsChanged(count)
+	}
+}
+
+// broadcast pushes an asset.reload notification to every connected client,
+// dropping (and closing) any connection that fails to accept the write.
+func (s *assetRPCServer) broadcast(event AssetEvent) {
+	data, err := json.Marshal(assetReloadNotification{JSONRPC: "2.0", Method: "asset.reload", Params: event})
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	conns := make([]net.Conn, 0, len(s.clients))
+	for conn := range s.clients {
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.SetWriteDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Write(data); err != nil {
+			s.removeClient(conn)
+		}
+	}
+}
+
+// ClientCount returns the number of currently connected clients.
+func (s *assetRPCServer) ClientCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.clients)
+}
+
+// Close stops accepting new connections. Already-connected clients are not
+// forcibly disconnected.
+func (s *assetRPCServer) Close() error {
+	return s.listener.Close()
+}
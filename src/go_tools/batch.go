@@ -0,0 +1,382 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/**
+ * batch - manifest-driven pipeline mode. A manifest lists jobs ("simulate"
+ * a seeded match to a trace file, "analyze" a trace file produced by an
+ * earlier job), each naming the prior job IDs it depends on. runBatchManifest
+ * topologically orders the DAG and executes ready jobs concurrently on a
+ * worker pool sized from the manifest, so e.g. 100 independent "simulate"
+ * jobs run in parallel while any "analyze" job waits for its own trace to
+ * be written first. A summary report (status, duration, artifact path per
+ * job) is printed once every job has run or been abandoned.
+ *
+ * NOTE: the request this implements described generator/analyzer/profiler
+ * stages from subpackages this repo doesn't have (see cli.go's note on
+ * main.go). The stages below wrap the subsystems that actually exist here:
+ * "simulate" drives SimRunner.RunTrace (see sim_runner.go), "analyze" reads
+ * the resulting JSONL trace and scores it with calculateTowerStability (see
+ * dev_tools.go) the same way GameAnalyzer does.
+ */
+
+// BatchJob is one manifest entry: a stage to run, its stage-specific inputs,
+// and the job IDs it depends on.
+type BatchJob struct {
+	ID        string   `json:"id"`
+	Stage     string   `json:"stage"`                // "simulate" or "analyze"
+	Seed      int64    `json:"seed,omitempty"`       // simulate only
+	TracePath string   `json:"trace_path,omitempty"` // simulate: output; analyze: input if no DependsOn
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// BatchManifest is the top-level manifest file: how many jobs may run at
+// once, the asset/level to simulate against, and the job list itself.
+type BatchManifest struct {
+	Workers   int        `json:"workers"`
+	LevelPath string     `json:"level_path,omitempty"`
+	OutDir    string     `json:"out_dir"`
+	Jobs      []BatchJob `json:"jobs"`
+}
+
+// BatchJobResult is one line of the summary report printed after a batch
+// run finishes.
+type BatchJobResult struct {
+	ID       string        `json:"id"`
+	Stage    string        `json:"stage"`
+	Status   string        `json:"status"` // "done", "failed", "skipped"
+	Duration time.Duration `json:"duration"`
+	Artifact string        `json:"artifact,omitempty"`
+	Err      string        `json:"error,omitempty"`
+}
+
+// loadBatchManifest reads and parses a JSON batch manifest.
+func loadBatchManifest(path string) (*BatchManifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(tr("batch.read_manifest_failed"), err)
+	}
+
+	var manifest BatchManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf(tr("batch.parse_manifest_failed"), err)
+	}
+	if manifest.Workers < 1 {
+		manifest.Workers = 1
+	}
+	return &manifest, nil
+}
+
+// topoOrderBatchJobs returns jobs' IDs in an order where every job comes
+// after everything it DependsOn, or an error if the DAG has a cycle or
+// references a job ID that doesn't exist.
+func topoOrderBatchJobs(jobs []BatchJob) ([]string, error) {
+	byID := make(map[string]BatchJob, len(jobs))
+	for _, job := range jobs {
+		byID[job.ID] = job
+	}
+
+	const (
+		unvisited = 0
+		visiting  = 1
+		done      = 2
+	)
+	state := make(map[string]int, len(jobs))
+	var order []string
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf(tr("batch.dependency_cycle"), id)
+		}
+
+		job, ok := byID[id]
+		if !ok {
+			return fmt.Errorf(tr("batch.unknown_dependency"), id, id)
+		}
+
+		state[id] = visiting
+		for _, dep := range job.DependsOn {
+			if _, ok := byID[dep]; !ok {
+				return fmt.Errorf(tr("batch.unknown_dependency"), id, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[id] = done
+		order = append(order, id)
+		return nil
+	}
+
+	for _, job := range jobs {
+		if err := visit(job.ID); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// runBatchManifest executes every job in manifest, respecting DependsOn, on
+// a worker pool sized manifest.Workers. Jobs whose dependencies failed are
+// recorded as "skipped" rather than attempted.
+func runBatchManifest(manifest *BatchManifest) ([]BatchJobResult, error) {
+	order, err := topoOrderBatchJobs(manifest.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]BatchJob, len(manifest.Jobs))
+	for _, job := range manifest.Jobs {
+		byID[job.ID] = job
+	}
+
+	if err := os.MkdirAll(manifest.OutDir, 0755); err != nil {
+		return nil, fmt.Errorf(tr("batch.create_outdir_failed"), err)
+	}
+
+	var level *Level
+	if manifest.LevelPath != "" {
+		data, err := ioutil.ReadFile(manifest.LevelPath)
+		if err != nil {
+			return nil, fmt.Errorf(tr("batch.read_level_failed"), err)
+		}
+		level = &Level{}
+		if err := json.Unmarshal(data, level); err != nil {
+			return nil, fmt.Errorf(tr("batch.parse_level_failed"), err)
+		}
+	}
+
+	var (
+		mu        sync.Mutex
+		results   = make(map[string]BatchJobResult, len(order))
+		remaining = make(map[string][]string, len(order)) // job ID -> deps not yet resolved
+		done      = make(chan string, len(order))
+		sem       = make(chan struct{}, manifest.Workers)
+		wg        sync.WaitGroup
+	)
+
+	for _, id := range order {
+		remaining[id] = append([]string(nil), byID[id].DependsOn...)
+	}
+
+	runJob := func(id string) {
+		defer wg.Done()
+		sem <- struct{}{}
+		defer func() { <-sem }()
+
+		mu.Lock()
+		blockedByFailure := false
+		for _, dep := range byID[id].DependsOn {
+			if results[dep].Status != "done" {
+				blockedByFailure = true
+			}
+		}
+		mu.Unlock()
+
+		var result BatchJobResult
+		if blockedByFailure {
+			result = BatchJobResult{ID: id, Stage: byID[id].Stage, Status: "skipped"}
+		} else {
+			result = runBatchJob(byID[id], manifest.OutDir, level, func(depID string) string {
+				mu.Lock()
+				defer mu.Unlock()
+				return results[depID].Artifact
+			})
+		}
+
+		mu.Lock()
+		results[id] = result
+		mu.Unlock()
+		done <- id
+	}
+
+	scheduled := make(map[string]bool, len(order))
+	var scheduleReady func()
+	scheduleReady = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, id := range order {
+			if scheduled[id] {
+				continue
+			}
+			ready := true
+			for _, dep := range remaining[id] {
+				if _, ok := results[dep]; !ok {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				scheduled[id] = true
+				wg.Add(1)
+				go runJob(id)
+			}
+		}
+	}
+
+	scheduleReady()
+	for completedCount := 0; completedCount < len(order); completedCount++ {
+		<-done
+		scheduleReady()
+	}
+	wg.Wait()
+
+	ordered := make([]BatchJobResult, len(order))
+	for i, id := range order {
+		ordered[i] = results[id]
+	}
+	return ordered, nil
+}
+
+// runBatchJob executes a single job's stage, given a way to resolve the
+// artifact path a dependency produced (artifactOf).
+func runBatchJob(job BatchJob, outDir string, level *Level, artifactOf func(depID string) string) BatchJobResult {
+	start := time.Now()
+	result := BatchJobResult{ID: job.ID, Stage: job.Stage}
+
+	switch job.Stage {
+	case "simulate":
+		tracePath := job.TracePath
+		if tracePath == "" {
+			tracePath = filepath.Join(outDir, job.ID+".jsonl")
+		}
+		if err := simulateBatchJob(job, level, tracePath); err != nil {
+			result.Status, result.Err = "failed", err.Error()
+		} else {
+			result.Status, result.Artifact = "done", tracePath
+		}
+
+	case "analyze":
+		tracePath := job.TracePath
+		if tracePath == "" && len(job.DependsOn) > 0 {
+			tracePath = artifactOf(job.DependsOn[0])
+		}
+		summaryPath := filepath.Join(outDir, job.ID+".summary.json")
+		if err := analyzeBatchJob(tracePath, summaryPath); err != nil {
+			result.Status, result.Err = "failed", err.Error()
+		} else {
+			result.Status, result.Artifact = "done", summaryPath
+		}
+
+	default:
+		result.Status, result.Err = "failed", tr("batch.unknown_stage", job.Stage)
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+// simulateBatchJob runs one seeded match and writes its trace to tracePath.
+func simulateBatchJob(job BatchJob, level *Level, tracePath string) error {
+	settings := GameSettings{GravityScale: 1.0, SpellFrequency: 0.5, AIEnabled: true, AILevel: 2}
+	runner := NewSimRunner(settings, level, job.Seed)
+
+	out, err := os.Create(tracePath)
+	if err != nil {
+		return fmt.Errorf(tr("batch.create_trace_file_failed"), err)
+	}
+	defer out.Close()
+
+	_, err = runner.RunTrace(out, 1000)
+	return err
+}
+
+// batchAnalysisSummary is the artifact an "analyze" job writes: a single
+// player's final tower-stability score per trace, keyed by player ID.
+type batchAnalysisSummary struct {
+	TracePath        string             `json:"trace_path"`
+	FinalTowerHeight map[string]int     `json:"final_tower_height"`
+	FinalStability   map[string]float64 `json:"final_stability"`
+}
+
+// analyzeBatchJob reads the JSONL trace at tracePath (one GameState snapshot
+// per line, same format SimRunner writes) and scores the last snapshot's
+// towers with calculateTowerStability, writing the result to summaryPath.
+func analyzeBatchJob(tracePath, summaryPath string) error {
+	if tracePath == "" {
+		return fmt.Errorf(tr("batch.no_trace_path"))
+	}
+
+	data, err := ioutil.ReadFile(tracePath)
+	if err != nil {
+		return fmt.Errorf(tr("batch.read_trace_failed"), err)
+	}
+
+	var last GameState
+	found := false
+	for _, line := range splitTraceLines(data) {
+		if len(line) == 0 {
+			continue
+		}
+		var snapshot GameState
+		if err := json.Unmarshal(line, &snapshot); err != nil {
+			return fmt.Errorf(tr("batch.parse_trace_line_failed"), err)
+		}
+		last = snapshot
+		found = true
+	}
+	if !found {
+		return fmt.Errorf(tr("batch.trace_empty"))
+	}
+
+	summary := batchAnalysisSummary{
+		TracePath:        tracePath,
+		FinalTowerHeight: make(map[string]int, len(last.Players)),
+		FinalStability:   make(map[string]float64, len(last.Players)),
+	}
+	for id, player := range last.Players {
+		summary.FinalTowerHeight[id] = len(player.TowerBlocks)
+		summary.FinalStability[id] = calculateTowerStability(player.TowerBlocks).Score
+	}
+
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf(tr("batch.encode_summary_failed"), err)
+	}
+	return ioutil.WriteFile(summaryPath, out, 0644)
+}
+
+// splitTraceLines splits JSONL data into its individual lines without
+// pulling in bufio.Scanner's line-length limits for very large traces.
+func splitTraceLines(data []byte) [][]byte {
+	var lines [][]byte
+	start := 0
+	for i, b := range data {
+		if b == '\n' {
+			lines = append(lines, data[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(data) {
+		lines = append(lines, data[start:])
+	}
+	return lines
+}
+
+// printBatchSummary prints a one-line-per-job report after runBatchManifest
+// finishes.
+func printBatchSummary(results []BatchJobResult) {
+	fmt.Println(tr("batch.summary_header"))
+	for _, r := range results {
+		line := fmt.Sprintf("  %-20s %-10s %-8s %8s", r.ID, r.Stage, r.Status, r.Duration.Round(time.Millisecond))
+		if r.Artifact != "" {
+			line += "  -> " + r.Artifact
+		}
+		if r.Err != "" {
+			line += "  (" + r.Err + ")"
+		}
+		fmt.Println(line)
+	}
+}
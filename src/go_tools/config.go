@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+/**
+ * config - layered configuration for the dev_tools binary. Earlier settings
+ * lived in a single file named by -config (read by loadConfig in the
+ * since-removed main.go); this
+ * loads, in increasing priority, a system-wide file, an XDG user config
+ * file, a repo-local file, TETRIS_*-prefixed environment variables, and
+ * finally CLI flags, merging field-by-field so a later source only
+ * overrides the fields it actually sets. configOrigins records which source
+ * won each field, so `dev_tools config print` can explain itself.
+ *
+ * NOTE: the request this implements asked for shibukawa/configdir or
+ * spf13/viper (plus YAML/TOML support) - neither is a dependency in go.mod,
+ * and the removed main.go's loadConfig referenced a utils.Config type from
+ * a subpackage this tree doesn't have (see cli.go's note on main.go).
+ * What's below merges JSON only, using encoding/json and os - the same
+ * layering shibukawa/configdir would give, minus the extra file formats.
+ */
+
+// AppConfig is the merged configuration dev_tools' subcommands read from:
+// headless and serve use its fields as their flags' own defaults (so a
+// config file or TETRIS_* env var works without having to pass the
+// equivalent flag every time), the GUI's asset manager reads AssetPath
+// directly, and `config print` reports the whole merged set with per-field
+// provenance. Every field has a zero value that means "unset", so a later
+// layer only overrides what it actually specifies.
+type AppConfig struct {
+	LogLevel       string `json:"log_level"`
+	LogFormat      string `json:"log_format"`
+	LogFile        string `json:"log_file"`
+	AssetPath      string `json:"asset_path"`
+	HeadlessOutDir string `json:"headless_out_dir"`
+	ServeAddr      string `json:"serve_addr"`
+}
+
+// defaultAppConfig is the config a fresh checkout gets with no config files,
+// env vars, or flags set at all.
+func defaultAppConfig() AppConfig {
+	return AppConfig{
+		LogLevel:       "info",
+		LogFormat:      "text",
+		AssetPath:      "./assets",
+		HeadlessOutDir: "./sim_traces",
+		ServeAddr:      ":8080",
+	}
+}
+
+// configOrigins maps each AppConfig field (by JSON tag name) to the source
+// that set it, in the order sources were applied.
+type configOrigins map[string]string
+
+// configLayerPaths returns, in increasing priority order, the config file
+// paths loadLayeredConfig reads - whether or not they exist.
+func configLayerPaths() []string {
+	paths := []string{filepath.Join("/etc", "tetris-tools", "config.json")}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configHome = filepath.Join(home, ".config")
+		}
+	}
+	if configHome != "" {
+		paths = append(paths, filepath.Join(configHome, "tetris-tools", "config.json"))
+	}
+
+	paths = append(paths, "./tetris-tools.json")
+	return paths
+}
+
+// loadLayeredConfig merges every layer - system file, XDG user file,
+// repo-local file, TETRIS_* env vars - over defaultAppConfig, and returns
+// the result along with which source set each field. CLI flags are merged
+// in separately by the caller: each subcommand has its own flag set, so it
+// passes the relevant cfg fields as its own flags' defaults, then calls
+// applyConfigFlagOverrides after fs.Parse so origins correctly attributes a
+// field to "flag:-name" instead of whichever layer happened to match the
+// flag's default value.
+func loadLayeredConfig() (AppConfig, configOrigins) {
+	cfg := defaultAppConfig()
+	origins := make(configOrigins)
+	for _, field := range configFieldNames {
+		origins[field] = "default"
+	}
+
+	for _, path := range configLayerPaths() {
+		mergeConfigFile(path, &cfg, origins)
+	}
+	applyConfigEnvOverrides(&cfg, origins)
+
+	return cfg, origins
+}
+
+// mergeConfigFile applies path's JSON fields onto cfg, recording path as the
+// origin for each field it sets. A missing file is not an error - it simply
+// contributes nothing.
+func mergeConfigFile(path string, cfg *AppConfig, origins configOrigins) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		appLogger.Warn("config: failed to parse file, skipping", "path", path, "error", err)
+		return
+	}
+
+	applyRawConfigFields(cfg, raw, origins, path)
+}
+
+// applyConfigEnvOverrides applies TETRIS_LOG_LEVEL, TETRIS_LOG_FORMAT,
+// TETRIS_LOG_FILE, TETRIS_ASSET_PATH, TETRIS_HEADLESS_OUT_DIR and
+// TETRIS_SERVE_ADDR on top of cfg.
+func applyConfigEnvOverrides(cfg *AppConfig, origins configOrigins) {
+	setIfPresent := func(envVar string, field *string, name string) {
+		if v, ok := os.LookupEnv(envVar); ok {
+			*field = v
+			origins[name] = "env:" + envVar
+		}
+	}
+	setIfPresent("TETRIS_LOG_LEVEL", &cfg.LogLevel, "log_level")
+	setIfPresent("TETRIS_LOG_FORMAT", &cfg.LogFormat, "log_format")
+	setIfPresent("TETRIS_LOG_FILE", &cfg.LogFile, "log_file")
+	setIfPresent("TETRIS_ASSET_PATH", &cfg.AssetPath, "asset_path")
+	setIfPresent("TETRIS_HEADLESS_OUT_DIR", &cfg.HeadlessOutDir, "headless_out_dir")
+	setIfPresent("TETRIS_SERVE_ADDR", &cfg.ServeAddr, "serve_addr")
+}
+
+// applyRawConfigFields copies whichever of AppConfig's JSON fields are
+// present in raw onto cfg, recording source for each.
+func applyRawConfigFields(cfg *AppConfig, raw map[string]json.RawMessage, origins configOrigins, source string) {
+	fields := map[string]*string{
+		"log_level":        &cfg.LogLevel,
+		"log_format":       &cfg.LogFormat,
+		"log_file":         &cfg.LogFile,
+		"asset_path":       &cfg.AssetPath,
+		"headless_out_dir": &cfg.HeadlessOutDir,
+		"serve_addr":       &cfg.ServeAddr,
+	}
+	for name, dest := range fields {
+		msg, ok := raw[name]
+		if !ok {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(msg, &value); err != nil {
+			continue
+		}
+		*dest = value
+		origins[name] = source
+	}
+}
+
+// applyConfigFlagOverrides marks, in origins, which of fs's flags the caller
+// actually passed on the command line - fieldByFlag maps a flag name to the
+// AppConfig field (by JSON tag) it backs. The flag's value is already in
+// effect by this point (it was parsed against the matching cfg field as its
+// default), so this only fixes up provenance: an explicitly-passed flag
+// should report "flag:-name", not whatever layer happened to supply the
+// default it overrode.
+func applyConfigFlagOverrides(fs *flag.FlagSet, origins configOrigins, fieldByFlag map[string]string) {
+	fs.Visit(func(f *flag.Flag) {
+		if field, ok := fieldByFlag[f.Name]; ok {
+			origins[field] = "flag:-" + f.Name
+		}
+	})
+}
+
+// logEffectiveConfig emits one debug log line per entry in fieldByFlag,
+// naming the source that won for each - a config file, a TETRIS_* env var,
+// a flag, or the built-in default. Subcommands call this after
+// applyConfigFlagOverrides so an operator chasing an unexpected setting
+// doesn't have to run `dev_tools config print` separately.
+func logEffectiveConfig(subcommand string, origins configOrigins, fieldByFlag map[string]string) {
+	for flagName, field := range fieldByFlag {
+		appLogger.Debug("effective config", "subcommand", subcommand, "flag", flagName, "field", field, "source", origins[field])
+	}
+}
+
+// configFieldNames are AppConfig's JSON field names, used to seed
+// configOrigins with every field name up front.
+var configFieldNames = []string{
+	"log_level", "log_format", "log_file",
+	"asset_path", "headless_out_dir", "serve_addr",
+}
+
+// runConfigCommand dispatches "dev_tools config print" and
+// "dev_tools config paths".
+func runConfigCommand(args []string) {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, tr("config.usage"))
+		os.Exit(1)
+	}
+
+	switch fs.Arg(0) {
+	case "print":
+		cfg, origins := loadLayeredConfig()
+		printEffectiveConfig(cfg, origins)
+	case "paths":
+		printConfigPaths()
+	default:
+		fmt.Fprintln(os.Stderr, tr("config.unknown_subcommand", fs.Arg(0)))
+		os.Exit(1)
+	}
+}
+
+// printEffectiveConfig prints the merged config with, for each field, which
+// source last set it.
+func printEffectiveConfig(cfg AppConfig, origins configOrigins) {
+	rows := []struct {
+		field string
+		value string
+	}{
+		{"log_level", cfg.LogLevel},
+		{"log_format", cfg.LogFormat},
+		{"log_file", cfg.LogFile},
+		{"asset_path", cfg.AssetPath},
+		{"headless_out_dir", cfg.HeadlessOutDir},
+		{"serve_addr", cfg.ServeAddr},
+	}
+	for _, row := range rows {
+		fmt.Println(tr("config.print_row", row.field, row.value, origins[row.field]))
+	}
+}
+
+// printConfigPaths prints every file loadLayeredConfig checks, in the order
+// it checks them, marking which ones actually exist.
+func printConfigPaths() {
+	for _, path := range configLayerPaths() {
+		status := tr("config.path_missing")
+		if _, err := os.Stat(path); err == nil {
+			status = tr("config.path_found")
+		}
+		fmt.Println(tr("config.paths_row", path, status))
+	}
+}
@@ -1,17 +1,26 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/color"
+	"io"
 	"io/ioutil"
-	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/tetris-towers/dev-tools/tetromino"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
@@ -27,17 +36,18 @@ import (
  * Реализовано на Go с использованием Fyne для GUI
  */
 
-// Типы тетромино
-type TetrominoType string
+// Типы тетромино - теперь лишь алиасы на пакет tetromino, где живут
+// bag-7 рандомайзер, SRS wall-kick таблицы и раскладка фигуры на клетки.
+type TetrominoType = tetromino.Type
 
 const (
-	TetrominoI TetrominoType = "I"
-	TetrominoJ TetrominoType = "J"
-	TetrominoL TetrominoType = "L"
-	TetrominoO TetrominoType = "O"
-	TetrominoS TetrominoType = "S"
-	TetrominoT TetrominoType = "T"
-	TetrominoZ TetrominoType = "Z"
+	TetrominoI = tetromino.I
+	TetrominoJ = tetromino.J
+	TetrominoL = tetromino.L
+	TetrominoO = tetromino.O
+	TetrominoS = tetromino.S
+	TetrominoT = tetromino.T
+	TetrominoZ = tetromino.Z
 )
 
 // Типы заклинаний
@@ -115,15 +125,20 @@ type GameState struct {
 
 // Структура для представления уровня
 type Level struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	GameMode    GameMode  `json:"game_mode"`
-	Blocks      []Block   `json:"blocks"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	GameMode     GameMode `json:"game_mode"`
+	Width        int      `json:"width"`
+	Height       int      `json:"height"`
+	Blocks       []Block  `json:"blocks"`
 	WinCondition string   `json:"win_condition"`
 }
 
-// Структура для представления настроек игры
+// Структура для представления настроек игры. SchemaVersion отслеживает
+// формат самого файла настроек (см. settings_schema.go) - не путать с
+// версией игры.
 type GameSettings struct {
+	SchemaVersion   int      `json:"schema_version"`
 	DefaultGameMode GameMode `json:"default_game_mode"`
 	GravityScale    float64  `json:"gravity_scale"`
 	SpellFrequency  float64  `json:"spell_frequency"`
@@ -139,59 +154,107 @@ type DevTools struct {
 	gameAnalyzer *GameAnalyzer
 	settingsEditor *SettingsEditor
 	assetManager *AssetManager
+	simRunner    *SimRunnerWindow
+
+	// assetPath is AppConfig.AssetPath as resolved by loadLayeredConfig at
+	// startup - the GUI has no -asset-path flag of its own, so this is the
+	// only way to point the asset manager somewhere other than ./assets
+	// without editing code.
+	assetPath string
 }
 
 // Структура для редактора уровней
 type LevelEditor struct {
-	window       fyne.Window
-	currentLevel *Level
-	blocks       []Block
-	grid         *canvas.Raster
-	selectedBlock *Block
+	window          fyne.Window
+	currentLevel    *Level
+	blocks          []Block
+	grid            *canvas.Raster
+	gridWidget      *editableGrid
+	selectedBlock   *Block
+	selectedIDs     map[int]bool // мультивыделение по shift-клику, всегда включает ID selectedBlock
 	blockProperties *widget.Form
+	propX           *widget.Entry
+	propY           *widget.Entry
+	propWidth       *widget.Entry
+	propHeight      *widget.Entry
+	propColor       *widget.Entry
+	propStatic      *widget.Check
+
+	history      [][]Block // ограниченный кольцевой буфер снапшотов le.blocks для undo/redo
+	historyIndex int        // позиция после применения последней мутации
 }
 
 // Структура для анализатора игры
 type GameAnalyzer struct {
 	window       fyne.Window
-	gameState    *GameState
+	snapshots    []GameState // полная временная шкала состояний (один тик - один элемент)
+	currentTick  int         // тик, на который сейчас отмотан скраббер
+	gameState    *GameState  // снимок на currentTick, для обратной совместимости со старым кодом
 	playerStats  map[int]PlayerStats
 	charts       map[string]*canvas.Raster
+
+	playing        bool
+	playSpeed      float64 // тиков в секунду при воспроизведении
+	stopPlayback   chan struct{}
+	timelineSlider *widget.Slider
+	tickLabel      *widget.Label
+	playPauseBtn   *widget.Button
 }
 
-// Структура для статистики игрока
+// Структура для статистики игрока. Каждый слайс - это временной ряд,
+// по одному значению за тик снапшота, а не единственное число на всю игру.
 type PlayerStats struct {
-	TowerHeight     []float64
-	TowerStability  []float64
-	Score           []int
-	SpellsUsed      int
-	BlocksPlaced    int
-	TimeAlive       float64
+	TowerHeight      []float64
+	TowerStability   []float64
+	Score            []int
+	SpellsUsed       int
+	BlocksPlaced     int
+	TimeAlive        float64
+	LastAtRiskBlocks []int   // ID блоков, у которых на последнем проанализированном тике отрицательный margin
+	LastSlipRisk     float64 // риск проскальзывания на последнем проанализированном тике
 }
 
 // Структура для редактора настроек
 type SettingsEditor struct {
-	window       fyne.Window
-	settings     *GameSettings
-	form         *widget.Form
+	window     fyne.Window
+	settings   *GameSettings
+	form       *widget.Form
+	spellRules SpellRules // магнитуды заклинаний, тянутся из JSON-файла правил
 }
 
 // Структура для менеджера ассетов
 type AssetManager struct {
-	window       fyne.Window
-	assetList    *widget.List
-	assetPreview *canvas.Image
-	assetPath    string
+	window           fyne.Window
+	assetList        *widget.List
+	assetPreview     *canvas.Image
+	previewText      *widget.RichText
+	previewContainer *fyne.Container
+	assetPath        string
+
+	thumbMu sync.Mutex
+	thumbs  map[string]string // путь ассета (относительно assetPath) -> путь к кэшированному превью в .thumbs/
+
+	// Live-reload: fsnotify-вотчер assetPath и JSON-RPC сервер, публикующий
+	// его события запущенному экземпляру игры (см. asset_watcher.go).
+	watcher      *AssetWatcher
+	clientsLabel *widget.Label
+
+	reloadLogMu   sync.Mutex
+	reloadLog     []string // последние пуши, самый новый - первым
+	reloadLogText *widget.Label
 }
 
 // Создание нового экземпляра инструментов разработки
 func NewDevTools() *DevTools {
+	cfg, _ := loadLayeredConfig()
+
 	a := app.New()
 	w := a.NewWindow("Tetris with Tricky Towers - Developer Tools")
-	
+
 	dt := &DevTools{
 		app:        a,
 		mainWindow: w,
+		assetPath:  cfg.AssetPath,
 	}
 	
 	dt.setupMainWindow()
@@ -217,7 +280,11 @@ func (dt *DevTools) setupMainWindow() {
 	assetManagerBtn := widget.NewButton("Asset Manager", func() {
 		dt.openAssetManager()
 	})
-	
+
+	simRunnerBtn := widget.NewButton("Simulation Runner", func() {
+		dt.openSimRunnerWindow()
+	})
+
 	// Создание контейнера с кнопками
 	content := container.NewVBox(
 		widget.NewLabel("Tetris with Tricky Towers - Developer Tools"),
@@ -226,6 +293,7 @@ func (dt *DevTools) setupMainWindow() {
 		gameAnalyzerBtn,
 		settingsEditorBtn,
 		assetManagerBtn,
+		simRunnerBtn,
 	)
 	
 	dt.mainWindow.SetContent(content)
@@ -243,12 +311,15 @@ func (dt *DevTools) openLevelEditor() {
 				Name:        "New Level",
 				Description: "A new level",
 				GameMode:    GameModeRace,
+				Width:       10,
+				Height:      20,
 				Blocks:      []Block{},
 				WinCondition: "height >= 15",
 			},
-			blocks:       []Block{},
+			blocks:      []Block{},
+			selectedIDs: make(map[int]bool),
 		}
-		
+
 		dt.levelEditor.setupUI()
 	}
 	
@@ -274,17 +345,25 @@ func (le *LevelEditor) setupUI() {
 			}
 		}
 		
+		// Блоки, у которых физический анализ устойчивости обнаружил
+		// отрицательный margin опоры - обводим их красным, чтобы дизайнер
+		// сразу видел, что башня в этом месте собирается упасть.
+		atRisk := make(map[int]bool)
+		for _, id := range calculateTowerStability(le.blocks).AtRiskBlocks {
+			atRisk[id] = true
+		}
+
 		// Рисование блоков
 		for _, block := range le.blocks {
 			x := int(block.X * 30)
 			y := int(block.Y * 30)
 			width := int(block.Width * 30)
 			height := int(block.Height * 30)
-			
+
 			// Парсинг цвета
 			var r, g, b uint8
 			fmt.Sscanf(block.Color, "#%02x%02x%02x", &r, &g, &b)
-			
+
 			// Рисование блока
 			for dx := 0; dx < width; dx++ {
 				for dy := 0; dy < height; dy++ {
@@ -293,24 +372,51 @@ func (le *LevelEditor) setupUI() {
 					}
 				}
 			}
+
+			if atRisk[block.ID] {
+				drawRect(img, x, y, width, height, color.RGBA{255, 0, 0, 255}, 2)
+			}
+			if le.selectedIDs[block.ID] {
+				drawRect(img, x, y, width, height, color.RGBA{0, 120, 255, 255}, 2)
+			}
 		}
-		
+
 		return img
 	})
-	
-	// Обработка кликов по сетке
+
 	le.grid.SetMinSize(fyne.NewSize(300, 600))
-	
-	// Создание формы для свойств блока
+	le.gridWidget = newEditableGrid(le)
+
+	// Создание формы для свойств блока. Поля привязаны к le.prop* вместо
+	// свежих NewEntry(), чтобы populatePropertiesForm/applyPropertiesForm
+	// могли их читать и заполнять.
+	le.propX = widget.NewEntry()
+	le.propY = widget.NewEntry()
+	le.propWidth = widget.NewEntry()
+	le.propHeight = widget.NewEntry()
+	le.propColor = widget.NewEntry()
+	le.propStatic = widget.NewCheck("", nil)
+
 	le.blockProperties = widget.NewForm(
-		widget.NewFormItem("X", widget.NewEntry()),
-		widget.NewFormItem("Y", widget.NewEntry()),
-		widget.NewFormItem("Width", widget.NewEntry()),
-		widget.NewFormItem("Height", widget.NewEntry()),
-		widget.NewFormItem("Color", widget.NewEntry()),
-		widget.NewFormItem("Is Static", widget.NewCheck("", nil)),
+		widget.NewFormItem("X", le.propX),
+		widget.NewFormItem("Y", le.propY),
+		widget.NewFormItem("Width", le.propWidth),
+		widget.NewFormItem("Height", le.propHeight),
+		widget.NewFormItem("Color", le.propColor),
+		widget.NewFormItem("Is Static", le.propStatic),
 	)
-	
+	le.blockProperties.OnSubmit = func() {
+		le.applyPropertiesForm()
+	}
+
+	undoBtn := widget.NewButton("Undo", func() {
+		le.undo()
+	})
+
+	redoBtn := widget.NewButton("Redo", func() {
+		le.redo()
+	})
+
 	// Кнопки для управления уровнем
 	newBtn := widget.NewButton("New Level", func() {
 		le.newLevel()
@@ -331,7 +437,14 @@ func (le *LevelEditor) setupUI() {
 	removeBlockBtn := widget.NewButton("Remove Block", func() {
 		le.removeBlock()
 	})
-	
+
+	tetrominoSelect := widget.NewSelect([]string{"I", "J", "L", "O", "S", "T", "Z"}, nil)
+	tetrominoSelect.SetSelected("I")
+
+	addTetrominoBtn := widget.NewButton("Stamp Tetromino", func() {
+		le.addTetromino(tetromino.Type(tetrominoSelect.Selected), 0, 3, 5, "#3366CC")
+	})
+
 	// Создание контейнера с кнопками
 	buttonContainer := container.NewHBox(
 		newBtn,
@@ -339,11 +452,17 @@ func (le *LevelEditor) setupUI() {
 		loadBtn,
 		addBlockBtn,
 		removeBlockBtn,
+		tetrominoSelect,
+		addTetrominoBtn,
+		undoBtn,
+		redoBtn,
 	)
-	
-	// Создание контейнера с формой и сеткой
+
+	// Создание контейнера с формой и сеткой. le.gridWidget оборачивает
+	// le.grid, добавляя клики/drag/правую кнопку - сам canvas.Raster не
+	// реагирует на ввод.
 	gridContainer := container.NewHSplit(
-		le.grid,
+		le.gridWidget,
 		le.blockProperties,
 	)
 	
@@ -371,7 +490,8 @@ func (le *LevelEditor) newLevel() {
 	le.grid.Refresh()
 }
 
-// Сохранение уровня
+// Сохранение уровня. Формат выбирается по расширению выбранного файла: ".map"
+// пишет компактный бинарный MAPSTRUCT-формат, всё остальное - как раньше, JSON.
 func (le *LevelEditor) saveLevel() {
 	// Создание диалога для выбора файла
 	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
@@ -379,35 +499,41 @@ func (le *LevelEditor) saveLevel() {
 			dialog.ShowError(err, le.window)
 			return
 		}
-		
+
 		if writer == nil {
 			return
 		}
-		
+
 		// Обновление блоков в уровне
 		le.currentLevel.Blocks = le.blocks
-		
-		// Сериализация уровня в JSON
-		data, err := json.MarshalIndent(le.currentLevel, "", "  ")
+
+		var data []byte
+		if strings.EqualFold(filepath.Ext(writer.URI().Name()), ".map") {
+			data, err = le.currentLevel.MarshalBinary()
+		} else {
+			data, err = json.MarshalIndent(le.currentLevel, "", "  ")
+		}
 		if err != nil {
 			dialog.ShowError(err, le.window)
 			return
 		}
-		
+
 		// Запись данных в файл
 		_, err = writer.Write(data)
 		if err != nil {
 			dialog.ShowError(err, le.window)
 			return
 		}
-		
+
 		writer.Close()
-		
+
 		dialog.ShowInformation("Success", "Level saved successfully", le.window)
 	}, le.window)
 }
 
-// Загрузка уровня
+// Загрузка уровня. Формат определяется не по расширению, а по магическим
+// байтам в начале файла - так перетащенный .map с чужим именем всё равно
+// загрузится правильно.
 func (le *LevelEditor) loadLevel() {
 	// Создание диалога для выбора файла
 	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
@@ -415,33 +541,38 @@ func (le *LevelEditor) loadLevel() {
 			dialog.ShowError(err, le.window)
 			return
 		}
-		
+
 		if reader == nil {
 			return
 		}
-		
+
 		// Чтение данных из файла
 		data, err := ioutil.ReadAll(reader)
 		if err != nil {
 			dialog.ShowError(err, le.window)
 			return
 		}
-		
+
 		reader.Close()
-		
-		// Десериализация уровня из JSON
+
+		// Десериализация уровня: бинарный .map или JSON, в зависимости от magic
 		var level Level
-		err = json.Unmarshal(data, &level)
+		if isLevelBinaryFormat(data) {
+			err = level.UnmarshalBinary(data)
+		} else {
+			err = json.Unmarshal(data, &level)
+		}
 		if err != nil {
 			dialog.ShowError(err, le.window)
 			return
 		}
-		
+
 		// Обновление текущего уровня и блоков
 		le.currentLevel = &level
 		le.blocks = level.Blocks
 		le.grid.Refresh()
-		
+
+		appLogger.Info("level loaded", "path", reader.URI().String(), "blocks", len(level.Blocks))
 		dialog.ShowInformation("Success", "Level loaded successfully", le.window)
 	}, le.window)
 }
@@ -463,11 +594,37 @@ func (le *LevelEditor) addBlock() {
 		IsStatic:   true,
 	}
 	
+	le.pushHistory()
+
 	// Добавление блока в список
 	le.blocks = append(le.blocks, block)
 	le.grid.Refresh()
 }
 
+// addTetromino штампует на сетку целую фигуру (4 клетки в раскладке SRS для
+// заданной ротации) вместо одной клетки 1x1, используя tetromino.Cells.
+func (le *LevelEditor) addTetromino(t tetromino.Type, rotation int, originX, originY float64, color string) {
+	le.pushHistory()
+
+	for _, cell := range tetromino.Cells(t, rotation) {
+		block := Block{
+			ID:          len(le.blocks),
+			X:           originX + float64(cell.X),
+			Y:           originY + float64(cell.Y),
+			Width:       1,
+			Height:      1,
+			Rotation:    0,
+			Color:       color,
+			Density:     1.0,
+			Friction:    0.3,
+			Restitution: 0.1,
+			IsStatic:    true,
+		}
+		le.blocks = append(le.blocks, block)
+	}
+	le.grid.Refresh()
+}
+
 // Удаление блока
 func (le *LevelEditor) removeBlock() {
 	if le.selectedBlock != nil {
@@ -482,7 +639,9 @@ func (le *LevelEditor) removeBlock() {
 		
 		// Удаление блока из списка
 		if index >= 0 {
+			le.pushHistory()
 			le.blocks = append(le.blocks[:index], le.blocks[index+1:]...)
+			delete(le.selectedIDs, le.selectedBlock.ID)
 			le.selectedBlock = nil
 			le.grid.Refresh()
 		}
@@ -499,6 +658,7 @@ func (dt *DevTools) openGameAnalyzer() {
 			gameState:   nil,
 			playerStats: make(map[int]PlayerStats),
 			charts:      make(map[string]*canvas.Raster),
+			playSpeed:   10.0,
 		}
 		
 		dt.gameAnalyzer.setupUI()
@@ -513,22 +673,22 @@ func (ga *GameAnalyzer) setupUI() {
 	loadBtn := widget.NewButton("Load Game Data", func() {
 		ga.loadGameData()
 	})
-	
+
 	analyzeBtn := widget.NewButton("Analyze Game", func() {
 		ga.analyzeGame()
 	})
-	
+
 	exportBtn := widget.NewButton("Export Analysis", func() {
 		ga.exportAnalysis()
 	})
-	
+
 	// Создание контейнера с кнопками
 	buttonContainer := container.NewHBox(
 		loadBtn,
 		analyzeBtn,
 		exportBtn,
 	)
-	
+
 	// Создание вкладок для различных графиков
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Tower Height", canvas.NewRaster(func(w, h int) image.Image {
@@ -541,21 +701,67 @@ func (ga *GameAnalyzer) setupUI() {
 			return ga.drawScoreChart(w, h)
 		})),
 	)
-	
+	ga.charts["height"] = tabs.Items[0].Content.(*canvas.Raster)
+	ga.charts["stability"] = tabs.Items[1].Content.(*canvas.Raster)
+	ga.charts["score"] = tabs.Items[2].Content.(*canvas.Raster)
+
+	// Скраббер таймлайна: отматывает снапшоты и перерисовывает графики до текущего тика
+	ga.tickLabel = widget.NewLabel("Tick 0 / 0")
+
+	ga.timelineSlider = widget.NewSlider(0, 0)
+	ga.timelineSlider.Step = 1
+	ga.timelineSlider.OnChanged = func(value float64) {
+		ga.seekTick(int(value))
+	}
+
+	ga.playPauseBtn = widget.NewButton("Play", func() {
+		if ga.playing {
+			ga.pausePlayback()
+		} else {
+			ga.startPlayback()
+		}
+	})
+
+	speedSelect := widget.NewSelect([]string{"0.5x", "1x", "2x", "4x", "8x"}, func(value string) {
+		switch value {
+		case "0.5x":
+			ga.playSpeed = 5.0
+		case "1x":
+			ga.playSpeed = 10.0
+		case "2x":
+			ga.playSpeed = 20.0
+		case "4x":
+			ga.playSpeed = 40.0
+		case "8x":
+			ga.playSpeed = 80.0
+		}
+	})
+	speedSelect.SetSelected("1x")
+
+	timelineContainer := container.NewBorder(
+		nil, nil,
+		container.NewHBox(ga.playPauseBtn, speedSelect),
+		ga.tickLabel,
+		ga.timelineSlider,
+	)
+
 	// Создание основного контейнера
 	content := container.NewBorder(
-		buttonContainer,
+		container.NewVBox(buttonContainer, timelineContainer),
 		nil,
 		nil,
 		nil,
 		tabs,
 	)
-	
+
 	ga.window.SetContent(content)
 	ga.window.Resize(fyne.NewSize(800, 600))
 }
 
-// Загрузка данных игры
+// Загрузка данных игры. Поддерживает два формата:
+//   - одиночный JSON-объект GameState (старый формат, одна точка на игрока);
+//   - JSONL-трейс, один снапшот GameState на строку, как его пишет SimRunner -
+//     тогда грузим построчно через bufio.Scanner, не читая весь файл в память.
 func (ga *GameAnalyzer) loadGameData() {
 	// Создание диалога для выбора файла
 	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
@@ -563,166 +769,340 @@ func (ga *GameAnalyzer) loadGameData() {
 			dialog.ShowError(err, ga.window)
 			return
 		}
-		
+
 		if reader == nil {
 			return
 		}
-		
-		// Чтение данных из файла
-		data, err := ioutil.ReadAll(reader)
+		defer reader.Close()
+
+		snapshots, err := decodeGameStateTrace(reader)
 		if err != nil {
 			dialog.ShowError(err, ga.window)
 			return
 		}
-		
-		reader.Close()
-		
-		// Десериализация состояния игры из JSON
-		var gameState GameState
-		err = json.Unmarshal(data, &gameState)
-		if err != nil {
-			dialog.ShowError(err, ga.window)
+		if len(snapshots) == 0 {
+			dialog.ShowInformation("Error", "No snapshots found in file", ga.window)
 			return
 		}
-		
-		// Обновление текущего состояния игры
-		ga.gameState = &gameState
-		
-		dialog.ShowInformation("Success", "Game data loaded successfully", ga.window)
+
+		ga.snapshots = snapshots
+		ga.seekTick(0)
+
+		if ga.timelineSlider != nil {
+			ga.timelineSlider.Max = float64(len(snapshots) - 1)
+			ga.timelineSlider.Value = 0
+			ga.timelineSlider.Refresh()
+		}
+
+		dialog.ShowInformation("Success", fmt.Sprintf("Loaded %d snapshot(s)", len(snapshots)), ga.window)
 	}, ga.window)
 }
 
-// Анализ игры
+// decodeGameStateTrace читает один снапшот GameState на строку (JSONL). Файлы
+// со старым форматом - один объект GameState без переносов строк - тоже
+// читаются этим сканером как единственная "строка" и дают трейс длиной 1.
+func decodeGameStateTrace(r io.Reader) ([]GameState, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var snapshots []GameState
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var state GameState
+		if err := json.Unmarshal(line, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot line: %w", err)
+		}
+		snapshots = append(snapshots, state)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// Анализ игры: строит полный временной ряд статистики по каждому игроку,
+// проходя все загруженные снапшоты от начала до currentTick включительно.
 func (ga *GameAnalyzer) analyzeGame() {
-	if ga.gameState == nil {
+	if len(ga.snapshots) == 0 {
 		dialog.ShowInformation("Error", "No game data loaded", ga.window)
 		return
 	}
-	
-	// Очистка предыдущих статистик
-	ga.playerStats = make(map[int]PlayerStats)
-	
-	// Анализ статистики для каждого игрока
-	for idStr, player := range ga.gameState.Players {
-		id, _ := strconv.Atoi(idStr)
-		
-		// Вычисление высоты башни
-		towerHeight := 0.0
-		for _, block := range player.TowerBlocks {
-			towerHeight = math.Max(towerHeight, 20-block.Y)
-		}
-		
-		// Вычисление стабильности башни
-		towerStability := ga.calculateTowerStability(player.TowerBlocks)
-		
-		// Создание статистики игрока
-		stats := PlayerStats{
-			TowerHeight:    []float64{towerHeight},
-			TowerStability: []float64{towerStability},
-			Score:          []int{player.Score},
-			SpellsUsed:     len(player.Spells),
-			BlocksPlaced:   len(player.TowerBlocks),
-			TimeAlive:      ga.gameState.Timer,
+
+	stats := make(map[int]PlayerStats)
+
+	for tick := 0; tick <= ga.currentTick && tick < len(ga.snapshots); tick++ {
+		snapshot := ga.snapshots[tick]
+
+		for idStr, player := range snapshot.Players {
+			id, _ := strconv.Atoi(idStr)
+
+			towerHeight := 0.0
+			for _, block := range player.TowerBlocks {
+				towerHeight = math.Max(towerHeight, 20-block.Y)
+			}
+			stability := calculateTowerStability(player.TowerBlocks)
+
+			s := stats[id]
+			s.TowerHeight = append(s.TowerHeight, towerHeight)
+			s.TowerStability = append(s.TowerStability, stability.Score)
+			s.Score = append(s.Score, player.Score)
+			s.SpellsUsed = len(player.Spells)
+			s.BlocksPlaced = len(player.TowerBlocks)
+			s.TimeAlive = snapshot.Timer
+			s.LastAtRiskBlocks = stability.AtRiskBlocks
+			s.LastSlipRisk = stability.SlipRisk
+			stats[id] = s
 		}
-		
-		ga.playerStats[id] = stats
 	}
-	
+
+	ga.playerStats = stats
+
 	// Обновление графиков
 	for _, chart := range ga.charts {
 		chart.Refresh()
 	}
-	
+
 	dialog.ShowInformation("Success", "Game analyzed successfully", ga.window)
 }
 
-// Вычисление стабильности башни
-func (ga *GameAnalyzer) calculateTowerStability(blocks []Block) float64 {
-	if len(blocks) == 0 {
-		return 1.0
+// seekTick отматывает скраббер на заданный тик, обновляет ga.gameState
+// (для обратной совместимости) и пересчитывает статистику/графики.
+func (ga *GameAnalyzer) seekTick(tick int) {
+	if len(ga.snapshots) == 0 {
+		return
 	}
-	
-	// Вычисление центра масс башни
-	totalMass := 0.0
-	weightedX := 0.0
-	
-	for _, block := range blocks {
-		mass := block.Width * block.Height * block.Density
-		totalMass += mass
-		weightedX += block.X * mass
+	if tick < 0 {
+		tick = 0
 	}
-	
-	centerOfMassX := weightedX / totalMass
-	
-	// Вычисление отклонения от центра поля
-	fieldCenterX := 5.0 // Центр поля по X
-	deviation := math.Abs(centerOfMassX - fieldCenterX)
-	
-	// Нормализация отклонения (0 - максимальная стабильность, 1 - минимальная)
-	maxDeviation := 5.0 // Максимально возможное отклонение
-	stability := 1.0 - (deviation / maxDeviation)
-	
-	return math.Max(0.0, math.Min(1.0, stability))
+	if tick >= len(ga.snapshots) {
+		tick = len(ga.snapshots) - 1
+	}
+
+	ga.currentTick = tick
+	ga.gameState = &ga.snapshots[tick]
+
+	if ga.tickLabel != nil {
+		ga.tickLabel.SetText(fmt.Sprintf("Tick %d / %d", tick, len(ga.snapshots)-1))
+	}
+
+	ga.analyzeGame()
 }
 
-// Рисование графика высоты башни
-func (ga *GameAnalyzer) drawTowerHeightChart(w, h int) image.Image {
-	img := image.NewRGBA(image.Rect(0, 0, w, h))
-	
-	// Заполнение фона
-	for x := 0; x < w; x++ {
-		for y := 0; y < h; y++ {
-			img.Set(x, y, color.RGBA{240, 240, 240, 255})
+// startPlayback запускает автоматическую прокрутку таймлайна со скоростью
+// ga.playSpeed тиков в секунду, пока не дойдёт до конца или не будет остановлена.
+func (ga *GameAnalyzer) startPlayback() {
+	if ga.playing || len(ga.snapshots) == 0 {
+		return
+	}
+
+	ga.playing = true
+	ga.stopPlayback = make(chan struct{})
+	if ga.playPauseBtn != nil {
+		ga.playPauseBtn.SetText("Pause")
+	}
+
+	stop := ga.stopPlayback
+	go func() {
+		for {
+			speed := ga.playSpeed
+			if speed <= 0 {
+				speed = 10.0
+			}
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / speed))
+
+			select {
+			case <-stop:
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				ticker.Stop()
+			}
+
+			next := ga.currentTick + 1
+			if next >= len(ga.snapshots) {
+				ga.pausePlayback()
+				return
+			}
+
+			ga.seekTick(next)
+			if ga.timelineSlider != nil {
+				ga.timelineSlider.Value = float64(next)
+				ga.timelineSlider.Refresh()
+			}
 		}
+	}()
+}
+
+// pausePlayback останавливает автоматическую прокрутку, начатую startPlayback.
+func (ga *GameAnalyzer) pausePlayback() {
+	if !ga.playing {
+		return
 	}
-	
-	// Рисование осей
-	for x := 0; x < w; x++ {
-		img.Set(x, h-50, color.RGBA{0, 0, 0, 255})
+
+	ga.playing = false
+	if ga.stopPlayback != nil {
+		close(ga.stopPlayback)
+		ga.stopPlayback = nil
 	}
-	
-	for y := 0; y < h; y++ {
-		img.Set(50, y, color.RGBA{0, 0, 0, 255})
+	if ga.playPauseBtn != nil {
+		ga.playPauseBtn.SetText("Play")
 	}
-	
-	// Рисование данных для каждого игрока
-	colors := []color.RGBA{
-		{255, 0, 0, 255},
-		{0, 255, 0, 255},
-		{0, 0, 255, 255},
-		{255, 255, 0, 255},
+}
+
+// StabilityResult - результат физического анализа устойчивости башни:
+// итоговый скор, блоки под угрозой опрокидывания и риск проскальзывания.
+type StabilityResult struct {
+	Score        float64 // min(margin_i / halfwidth_i) по всем контактам, зажатый в [0,1]
+	AtRiskBlocks []int   // ID блоков, чей контакт имеет отрицательный margin
+	SlipRisk     float64 // в [0,1]; >0 значит где-то срезающая сила превышает friction*normal
+}
+
+// fieldGroundY - Y-координата пола игрового поля (высота поля = 20 клеток,
+// Y блока растёт вниз, см. towerHeight = 20 - block.Y выше).
+const fieldGroundY = 20.0
+
+// contactEpsilon - допуск по Y при определении "блок B лежит на блоке A".
+const contactEpsilon = 0.25
+
+// calculateTowerStability - физически приближённая метрика устойчивости в духе
+// Tricky Towers: проходит башню снизу вверх, для каждой пары контактирующих
+// блоков проецирует суммарный центр масс "всего, что лежит выше" на ось X и
+// проверяет, попадает ли проекция в интервал перекрытия опорных footprint'ов
+// (или в пол, если блок ничем не подпёрт). margin_i - это подписанное
+// расстояние от проекции до ближайшего края интервала; score - минимум
+// margin_i/halfwidth_i по всем контактам. Отрицательный margin означает, что
+// центр масс вышел за пределы опоры - такой блок попадает в AtRiskBlocks.
+func calculateTowerStability(blocks []Block) StabilityResult {
+	if len(blocks) == 0 {
+		return StabilityResult{Score: 1.0}
 	}
-	
-	i := 0
-	for _, stats := range ga.playerStats {
-		if len(stats.TowerHeight) > 0 {
-			// Нормализация данных
-			maxHeight := 20.0
-			normalizedHeight := stats.TowerHeight[0] / maxHeight
-			
-			// Рисование точки
-			x := 50 + int(float64(w-100)*0.5)
-			y := h - 50 - int(float64(h-100)*normalizedHeight)
-			
-			// Рисование круга
-			radius := 5
-			for dx := -radius; dx <= radius; dx++ {
-				for dy := -radius; dy <= radius; dy++ {
-					if dx*dx+dy*dy <= radius*radius {
-						img.Set(x+dx, y+dy, colors[i%len(colors)])
-					}
-				}
+
+	mass := make(map[int]float64, len(blocks))
+	for _, b := range blocks {
+		mass[b.ID] = b.Width * b.Height * b.Density
+	}
+
+	// Снизу вверх: блок ближе к полу имеет больший Y.
+	bottomUp := append([]Block(nil), blocks...)
+	sort.Slice(bottomUp, func(i, j int) bool { return bottomUp[i].Y > bottomUp[j].Y })
+
+	// Для каждого блока находим опору - блок прямо под ним с наибольшим
+	// горизонтальным перекрытием, либо отмечаем, что он стоит на полу.
+	supportOf := make(map[int]Block)
+	onGround := make(map[int]bool)
+	for _, b := range bottomUp {
+		var best Block
+		bestOverlap := 0.0
+		found := false
+		for _, c := range blocks {
+			if c.ID == b.ID || math.Abs(c.Y-(b.Y+b.Height)) > contactEpsilon {
+				continue
 			}
-			
-			i++
+			if overlap := horizontalOverlap(b, c); overlap > bestOverlap {
+				bestOverlap, best, found = overlap, c, true
+			}
+		}
+		if found {
+			supportOf[b.ID] = best
+		} else if b.Y+b.Height >= fieldGroundY-contactEpsilon {
+			onGround[b.ID] = true
 		}
 	}
-	
-	return img
+
+	// Масса и взвешенная X-координата "стопки" каждого блока - этого блока
+	// плюс всего, что на нём (прямо или опосредованно) покоится. Считаем от
+	// верхних блоков к нижним, чтобы дети были уже просуммированы к моменту,
+	// когда их вклад добавляется к родителю.
+	stackedMass := make(map[int]float64, len(blocks))
+	stackedWeightedX := make(map[int]float64, len(blocks))
+	for _, b := range blocks {
+		stackedMass[b.ID] = mass[b.ID]
+		stackedWeightedX[b.ID] = mass[b.ID] * (b.X + b.Width/2)
+	}
+	topDown := append([]Block(nil), bottomUp...)
+	for i, j := 0, len(topDown)-1; i < j; i, j = i+1, j-1 {
+		topDown[i], topDown[j] = topDown[j], topDown[i]
+	}
+	for _, b := range topDown {
+		if parent, ok := supportOf[b.ID]; ok {
+			stackedMass[parent.ID] += stackedMass[b.ID]
+			stackedWeightedX[parent.ID] += stackedWeightedX[b.ID]
+		}
+	}
+
+	minRatio := math.Inf(1)
+	slipRisk := 0.0
+	var atRisk []int
+
+	for _, b := range blocks {
+		var lo, hi float64
+		switch parent, supported := supportOf[b.ID]; {
+		case supported:
+			lo = math.Max(b.X, parent.X)
+			hi = math.Min(b.X+b.Width, parent.X+parent.Width)
+		case onGround[b.ID]:
+			lo, hi = b.X, b.X+b.Width
+		default:
+			continue // ничем не подпёртый блок (например, падающее тетромино)
+		}
+		if hi <= lo {
+			continue
+		}
+
+		halfwidth := (hi - lo) / 2
+		center := (lo + hi) / 2
+		comX := stackedWeightedX[b.ID] / stackedMass[b.ID]
+		margin := halfwidth - math.Abs(comX-center)
+		ratio := margin / halfwidth
+
+		if ratio < minRatio {
+			minRatio = ratio
+		}
+		if margin < 0 {
+			atRisk = append(atRisk, b.ID)
+		}
+
+		// Риск проскальзывания: срезающая сила растёт с перевесом центра масс
+		// за пределы опоры, удерживающая сила - friction * нормальная сила.
+		overhangFraction := math.Abs(comX-center) / halfwidth
+		shearForce := stackedMass[b.ID] * overhangFraction
+		frictionLimit := b.Friction * stackedMass[b.ID]
+		if shearForce > frictionLimit {
+			if risk := (shearForce - frictionLimit) / stackedMass[b.ID]; risk > slipRisk {
+				slipRisk = risk
+			}
+		}
+	}
+
+	if math.IsInf(minRatio, 1) {
+		minRatio = 1.0
+	}
+
+	return StabilityResult{
+		Score:        math.Max(0.0, math.Min(1.0, minRatio)),
+		AtRiskBlocks: atRisk,
+		SlipRisk:     math.Max(0.0, math.Min(1.0, slipRisk)),
+	}
 }
 
-// Рисование графика стабильности башни
-func (ga *GameAnalyzer) drawTowerStabilityChart(w, h int) image.Image {
+// horizontalOverlap возвращает ширину пересечения горизонтальных footprint'ов
+// двух блоков (0, если они не перекрываются).
+func horizontalOverlap(a, b Block) float64 {
+	lo := math.Max(a.X, b.X)
+	hi := math.Min(a.X+a.Width, b.X+b.Width)
+	if hi <= lo {
+		return 0
+	}
+	return hi - lo
+}
+
+// Рисование графика высоты башни
+func (ga *GameAnalyzer) drawTowerHeightChart(w, h int) image.Image {
 	img := image.NewRGBA(image.Rect(0, 0, w, h))
 	
 	// Заполнение фона
@@ -751,28 +1131,145 @@ func (ga *GameAnalyzer) drawTowerStabilityChart(w, h int) image.Image {
 	
 	i := 0
 	for _, stats := range ga.playerStats {
-		if len(stats.TowerStability) > 0 {
-			// Нормализация данных
-			normalizedStability := stats.TowerStability[0]
-			
-			// Рисование точки
-			x := 50 + int(float64(w-100)*0.5)
-			y := h - 50 - int(float64(h-100)*normalizedStability)
-			
-			// Рисование круга
-			radius := 5
-			for dx := -radius; dx <= radius; dx++ {
-				for dy := -radius; dy <= radius; dy++ {
-					if dx*dx+dy*dy <= radius*radius {
-						img.Set(x+dx, y+dy, colors[i%len(colors)])
-					}
-				}
-			}
-			
-			i++
-		}
+		drawTimeSeries(img, w, h, stats.TowerHeight, 20.0, colors[i%len(colors)])
+		i++
 	}
-	
+
+	return img
+}
+
+// drawTimeSeries рисует один временной ряд в виде ломаной линии от начала
+// истории (левый край графика) до последнего значения (текущий тик).
+// series нормализуется делением на max, затем вписывается в область осей.
+func drawTimeSeries(img *image.RGBA, w, h int, series []float64, max float64, col color.RGBA) {
+	if len(series) == 0 {
+		return
+	}
+	if max <= 0 {
+		max = 1.0
+	}
+
+	plotX0, plotX1 := 50, w-10
+	plotY0, plotY1 := 10, h-50
+	if plotX1 <= plotX0 || plotY1 <= plotY0 {
+		return
+	}
+
+	pointX := func(i int) int {
+		if len(series) == 1 {
+			return plotX0
+		}
+		return plotX0 + (plotX1-plotX0)*i/(len(series)-1)
+	}
+	pointY := func(v float64) int {
+		normalized := math.Max(0.0, math.Min(1.0, v/max))
+		return plotY1 - int(float64(plotY1-plotY0)*normalized)
+	}
+
+	prevX, prevY := pointX(0), pointY(series[0])
+	for i := 1; i < len(series); i++ {
+		x, y := pointX(i), pointY(series[i])
+		drawLine(img, prevX, prevY, x, y, col)
+		prevX, prevY = x, y
+	}
+
+	// Отметка текущего (последнего) значения кружком
+	radius := 4
+	for dx := -radius; dx <= radius; dx++ {
+		for dy := -radius; dy <= radius; dy++ {
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(prevX+dx, prevY+dy, col)
+			}
+		}
+	}
+}
+
+// drawRect рисует прямоугольную рамку толщиной thickness пикселей поверх
+// уже нарисованного содержимого - используется для подсветки блоков.
+func drawRect(img *image.RGBA, x, y, width, height int, col color.RGBA, thickness int) {
+	bounds := img.Bounds()
+	setIfInBounds := func(px, py int) {
+		if px >= bounds.Min.X && px < bounds.Max.X && py >= bounds.Min.Y && py < bounds.Max.Y {
+			img.Set(px, py, col)
+		}
+	}
+
+	for t := 0; t < thickness; t++ {
+		for dx := 0; dx < width; dx++ {
+			setIfInBounds(x+dx, y+t)
+			setIfInBounds(x+dx, y+height-1-t)
+		}
+		for dy := 0; dy < height; dy++ {
+			setIfInBounds(x+t, y+dy)
+			setIfInBounds(x+width-1-t, y+dy)
+		}
+	}
+}
+
+// drawLine рисует отрезок между двумя точками по алгоритму Брезенхэма.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, col color.RGBA) {
+	dx := int(math.Abs(float64(x1 - x0)))
+	dy := -int(math.Abs(float64(y1 - y0)))
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+
+	for {
+		img.Set(x0, y0, col)
+		if x0 == x1 && y0 == y1 {
+			break
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Рисование графика стабильности башни
+func (ga *GameAnalyzer) drawTowerStabilityChart(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	
+	// Заполнение фона
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{240, 240, 240, 255})
+		}
+	}
+	
+	// Рисование осей
+	for x := 0; x < w; x++ {
+		img.Set(x, h-50, color.RGBA{0, 0, 0, 255})
+	}
+	
+	for y := 0; y < h; y++ {
+		img.Set(50, y, color.RGBA{0, 0, 0, 255})
+	}
+	
+	// Рисование данных для каждого игрока
+	colors := []color.RGBA{
+		{255, 0, 0, 255},
+		{0, 255, 0, 255},
+		{0, 0, 255, 255},
+		{255, 255, 0, 255},
+	}
+	
+	i := 0
+	for _, stats := range ga.playerStats {
+		drawTimeSeries(img, w, h, stats.TowerStability, 1.0, colors[i%len(colors)])
+		i++
+	}
+
 	return img
 }
 
@@ -804,38 +1301,26 @@ func (ga *GameAnalyzer) drawScoreChart(w, h int) image.Image {
 		{255, 255, 0, 255},
 	}
 	
-	// Нахождение максимального значения очков
+	// Нахождение максимального значения очков за всю историю
 	maxScore := 1
 	for _, stats := range ga.playerStats {
-		if len(stats.Score) > 0 && stats.Score[0] > maxScore {
-			maxScore = stats.Score[0]
+		for _, score := range stats.Score {
+			if score > maxScore {
+				maxScore = score
+			}
 		}
 	}
-	
+
 	i := 0
 	for _, stats := range ga.playerStats {
-		if len(stats.Score) > 0 {
-			// Нормализация данных
-			normalizedScore := float64(stats.Score[0]) / float64(maxScore)
-			
-			// Рисование точки
-			x := 50 + int(float64(w-100)*0.5)
-			y := h - 50 - int(float64(h-100)*normalizedScore)
-			
-			// Рисование круга
-			radius := 5
-			for dx := -radius; dx <= radius; dx++ {
-				for dy := -radius; dy <= radius; dy++ {
-					if dx*dx+dy*dy <= radius*radius {
-						img.Set(x+dx, y+dy, colors[i%len(colors)])
-					}
-				}
-			}
-			
-			i++
+		scoreSeries := make([]float64, len(stats.Score))
+		for j, score := range stats.Score {
+			scoreSeries[j] = float64(score)
 		}
+		drawTimeSeries(img, w, h, scoreSeries, float64(maxScore), colors[i%len(colors)])
+		i++
 	}
-	
+
 	return img
 }
 
@@ -862,24 +1347,25 @@ func (ga *GameAnalyzer) exportAnalysis() {
 		report += "====================\n\n"
 		report += fmt.Sprintf("Game Mode: %s\n", ga.gameState.GameMode)
 		report += fmt.Sprintf("Game Duration: %.2f seconds\n", ga.gameState.Timer)
-		report += fmt.Sprintf("Number of Players: %d\n\n", len(ga.gameState.Players))
-		
-		// Добавление статистики для каждого игрока
+		report += fmt.Sprintf("Number of Players: %d\n", len(ga.gameState.Players))
+		report += fmt.Sprintf("Ticks Analyzed: %d / %d\n\n", ga.currentTick+1, len(ga.snapshots))
+
+		// Добавление статистики для каждого игрока (последнее значение ряда на currentTick)
 		for idStr, player := range ga.gameState.Players {
 			id, _ := strconv.Atoi(idStr)
 			stats := ga.playerStats[id]
-			
+
 			report += fmt.Sprintf("Player: %s (ID: %s)\n", player.Name, idStr)
 			report += fmt.Sprintf("Score: %d\n", player.Score)
-			
+
 			if len(stats.TowerHeight) > 0 {
-				report += fmt.Sprintf("Tower Height: %.2f\n", stats.TowerHeight[0])
+				report += fmt.Sprintf("Tower Height: %.2f\n", stats.TowerHeight[len(stats.TowerHeight)-1])
 			}
-			
+
 			if len(stats.TowerStability) > 0 {
-				report += fmt.Sprintf("Tower Stability: %.2f\n", stats.TowerStability[0])
+				report += fmt.Sprintf("Tower Stability: %.2f\n", stats.TowerStability[len(stats.TowerStability)-1])
 			}
-			
+
 			report += fmt.Sprintf("Blocks Placed: %d\n", stats.BlocksPlaced)
 			report += fmt.Sprintf("Spells Used: %d\n", stats.SpellsUsed)
 			report += fmt.Sprintf("Time Alive: %.2f seconds\n\n", stats.TimeAlive)
@@ -906,12 +1392,14 @@ func (dt *DevTools) openSettingsEditor() {
 		dt.settingsEditor = &SettingsEditor{
 			window:   w,
 			settings: &GameSettings{
+				SchemaVersion:   currentSettingsSchemaVersion,
 				DefaultGameMode: GameModeRace,
 				GravityScale:    1.0,
 				SpellFrequency:  0.5,
 				AIEnabled:       true,
 				AILevel:         2,
 			},
+			spellRules: defaultSpellRules(),
 		}
 		
 		dt.settingsEditor.setupUI()
@@ -968,30 +1456,133 @@ func (se *SettingsEditor) setupUI() {
 	saveBtn := widget.NewButton("Save Settings", func() {
 		se.saveSettings()
 	})
-	
+
 	loadBtn := widget.NewButton("Load Settings", func() {
 		se.loadSettings()
 	})
-	
+
 	resetBtn := widget.NewButton("Reset to Defaults", func() {
 		se.resetSettings()
 	})
-	
+
+	exportSchemaBtn := widget.NewButton("Export JSON Schema", func() {
+		se.exportSchema()
+	})
+
 	// Создание контейнера с кнопками
 	buttonContainer := container.NewHBox(
 		saveBtn,
 		loadBtn,
 		resetBtn,
+		exportSchemaBtn,
 	)
-	
+
+	// Заклинания: загрузка правил из JSON и живой "test spell" превью
+	loadRulesBtn := widget.NewButton("Load Spell Rules", func() {
+		se.loadSpellRules()
+	})
+
+	spellNames := []string{
+		string(SpellReinforce), string(SpellStabilize), string(SpellEnlarge),
+		string(SpellShrink), string(SpellLevitate), string(SpellEarthquake),
+		string(SpellWind), string(SpellSlippery), string(SpellConfusion),
+		string(SpellAccelerate),
+	}
+	testSpellSelect := widget.NewSelect(spellNames, nil)
+	testSpellSelect.SetSelected(spellNames[0])
+
+	testSpellBtn := widget.NewButton("Test Spell", func() {
+		se.testSpell(SpellType(testSpellSelect.Selected))
+	})
+
+	spellContainer := container.NewHBox(loadRulesBtn, testSpellSelect, testSpellBtn)
+
 	// Создание основного контейнера
 	content := container.NewVBox(
 		se.form,
 		buttonContainer,
+		widget.NewSeparator(),
+		spellContainer,
 	)
-	
+
 	se.window.SetContent(content)
-	se.window.Resize(fyne.NewSize(400, 300))
+	se.window.Resize(fyne.NewSize(500, 350))
+}
+
+// loadSpellRules загружает JSON-файл правил заклинаний и накладывает его
+// поверх встроенных значений по умолчанию - позволяет дизайнеру подстроить
+// магнитуды (амплитуда землетрясения, сила ветра, порог массы для левитации)
+// без перекомпиляции инструмента.
+func (se *SettingsEditor) loadSpellRules() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, se.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(err, se.window)
+			return
+		}
+
+		rules := defaultSpellRules()
+		var overrides SpellRules
+		if err := json.Unmarshal(data, &overrides); err != nil {
+			dialog.ShowError(err, se.window)
+			return
+		}
+		for spell, rule := range overrides {
+			rules[spell] = rule
+		}
+		se.spellRules = rules
+
+		dialog.ShowInformation("Success", "Spell rules loaded successfully", se.window)
+	}, se.window)
+}
+
+// testSpell строит превью-состояние с одной небольшой башней и применяет к
+// ней выбранное заклинание прямо в окне, чтобы дизайнер мог сразу увидеть
+// эффект магнитуды, не запуская игру.
+func (se *SettingsEditor) testSpell(spellType SpellType) {
+	preview := GameState{
+		Players: map[string]Player{
+			"1": {
+				ID:   1,
+				Name: "Preview",
+				TowerBlocks: []Block{
+					{ID: 0, X: 4, Y: 19, Width: 1, Height: 1, Density: 1.0, Friction: 0.3, IsStatic: true},
+					{ID: 1, X: 4.5, Y: 18, Width: 1, Height: 1, Density: 1.0, Friction: 0.3, IsStatic: true},
+					{ID: 2, X: 5, Y: 17, Width: 1, Height: 1, Density: 1.0, Friction: 0.3, IsStatic: true},
+				},
+			},
+			"2": {ID: 2, Name: "Opponent", TowerBlocks: []Block{
+				{ID: 0, X: 4, Y: 19, Width: 1, Height: 1, Density: 1.0, Friction: 0.3, IsStatic: true},
+			}},
+		},
+	}
+
+	effect, err := ApplySpell(spellType, se.spellRules, &preview, 1)
+	if err != nil {
+		dialog.ShowError(err, se.window)
+		return
+	}
+
+	after := preview.Players["1"].TowerBlocks
+	opponentAfter := preview.Players["2"].TowerBlocks
+
+	dialog.ShowInformation(
+		"Spell Preview",
+		fmt.Sprintf(
+			"%s (duration %.1fs)\nCaster blocks: %d\nOpponent blocks: %d\nFirst caster block X: %.2f",
+			spellType, effect.Duration().Seconds(), len(after), len(opponentAfter), after[0].X,
+		),
+		se.window,
+	)
 }
 
 // Сохранение настроек
@@ -1027,6 +1618,35 @@ func (se *SettingsEditor) saveSettings() {
 	}, se.window)
 }
 
+// exportSchema записывает JSON Schema для GameSettings в выбранный файл,
+// чтобы внешние инструменты могли валидировать конфиги без сборки DevTools.
+func (se *SettingsEditor) exportSchema() {
+	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, se.window)
+			return
+		}
+
+		if writer == nil {
+			return
+		}
+
+		data, err := json.MarshalIndent(gameSettingsJSONSchema(), "", "  ")
+		if err != nil {
+			dialog.ShowError(err, se.window)
+			return
+		}
+
+		if _, err := writer.Write(data); err != nil {
+			dialog.ShowError(err, se.window)
+			return
+		}
+		writer.Close()
+
+		dialog.ShowInformation("Success", "JSON Schema exported successfully", se.window)
+	}, se.window)
+}
+
 // Загрузка настроек
 func (se *SettingsEditor) loadSettings() {
 	// Создание диалога для выбора файла
@@ -1049,20 +1669,30 @@ func (se *SettingsEditor) loadSettings() {
 		
 		reader.Close()
 		
-		// Десериализация настроек из JSON
-		var settings GameSettings
-		err = json.Unmarshal(data, &settings)
+		// Десериализация настроек из JSON, с миграцией старых схем и
+		// валидацией полей
+		settings, issues, err := decodeGameSettings(data)
 		if err != nil {
 			dialog.ShowError(err, se.window)
 			return
 		}
-		
-		// Обновление текущих настроек
+
+		if len(issues) > 0 {
+			// Некоторые поля вышли за допустимые границы - даём пользователю
+			// посмотреть на диф и решить, принимать ли исправленные значения.
+			dialog.ShowConfirm("Settings out of range", formatSettingsIssues(issues), func(accept bool) {
+				if !accept {
+					return
+				}
+				se.settings = &settings
+				se.setupUI()
+				dialog.ShowInformation("Success", "Settings loaded with clamped values", se.window)
+			}, se.window)
+			return
+		}
+
 		se.settings = &settings
-		
-		// Обновление UI
 		se.setupUI()
-		
 		dialog.ShowInformation("Success", "Settings loaded successfully", se.window)
 	}, se.window)
 }
@@ -1071,13 +1701,14 @@ func (se *SettingsEditor) loadSettings() {
 func (se *SettingsEditor) resetSettings() {
 	// Сброс настроек к значениям по умолчанию
 	se.settings = &GameSettings{
+		SchemaVersion:   currentSettingsSchemaVersion,
 		DefaultGameMode: GameModeRace,
 		GravityScale:    1.0,
 		SpellFrequency:  0.5,
 		AIEnabled:       true,
 		AILevel:         2,
 	}
-	
+
 	// Обновление UI
 	se.setupUI()
 	
@@ -1091,10 +1722,13 @@ func (dt *DevTools) openAssetManager() {
 		
 		dt.assetManager = &AssetManager{
 			window:    w,
-			assetPath: "./assets",
+			assetPath: dt.assetPath,
+			thumbs:    make(map[string]string),
 		}
-		
+
 		dt.assetManager.setupUI()
+		dt.assetManager.generateThumbnails()
+		dt.assetManager.startLiveReload(defaultAssetReloadPort)
 	}
 	
 	dt.assetManager.window.Show()
@@ -1102,22 +1736,37 @@ func (dt *DevTools) openAssetManager() {
 
 // Настройка UI для менеджера ассетов
 func (am *AssetManager) setupUI() {
-	// Создание списка ассетов
+	// Создание списка ассетов: каждая строка - миниатюра (если уже
+	// закэширована в .thumbs/) плюс имя файла.
 	am.assetList = widget.NewList(
 		func() int {
 			return len(am.getAssetFiles())
 		},
 		func() fyne.CanvasObject {
-			return widget.NewLabel("Template")
+			thumb := canvas.NewImageFromFile("")
+			thumb.FillMode = canvas.ImageFillContain
+			thumb.SetMinSize(fyne.NewSize(32, 32))
+			return container.NewHBox(thumb, widget.NewLabel("Template"))
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
 			files := am.getAssetFiles()
-			if id < len(files) {
-				obj.(*widget.Label).SetText(files[id])
+			if id >= len(files) {
+				return
 			}
+			row := obj.(*fyne.Container)
+			thumb := row.Objects[0].(*canvas.Image)
+			label := row.Objects[1].(*widget.Label)
+
+			label.SetText(files[id])
+
+			am.thumbMu.Lock()
+			thumbPath := am.thumbs[files[id]]
+			am.thumbMu.Unlock()
+			thumb.File = thumbPath
+			thumb.Refresh()
 		},
 	)
-	
+
 	// Обработка выбора ассета
 	am.assetList.OnSelected = func(id widget.ListItemID) {
 		files := am.getAssetFiles()
@@ -1125,11 +1774,19 @@ func (am *AssetManager) setupUI() {
 			am.loadAssetPreview(files[id])
 		}
 	}
-	
-	// Создание предпросмотра ассета
+
+	// Создание предпросмотра ассета: am.assetPreview показывает изображения
+	// и кэшированные превью видео/аудио, am.previewText - подсвеченный
+	// текст для JSON/Lua/простых текстовых ассетов. previewContainer
+	// переключает между ними в зависимости от типа выбранного файла.
 	am.assetPreview = canvas.NewImageFromFile("")
 	am.assetPreview.FillMode = canvas.ImageFillContain
-	
+
+	am.previewText = widget.NewRichText()
+	am.previewText.Wrapping = fyne.TextWrapWord
+
+	am.previewContainer = container.NewStack(am.assetPreview)
+
 	// Кнопки для управления ассетами
 	importBtn := widget.NewButton("Import Asset", func() {
 		am.importAsset()
@@ -1142,24 +1799,35 @@ func (am *AssetManager) setupUI() {
 	deleteBtn := widget.NewButton("Delete Asset", func() {
 		am.deleteAsset()
 	})
-	
+
+	resetBtn := widget.NewButton("Reset to Default", func() {
+		am.resetAssetToDefault()
+	})
+
 	// Создание контейнера с кнопками
 	buttonContainer := container.NewHBox(
 		importBtn,
 		exportBtn,
 		deleteBtn,
+		resetBtn,
 	)
-	
+
+	// Индикатор подключённых к live-reload клиентов и лог последних
+	// отправленных им событий (см. asset_watcher.go).
+	am.clientsLabel = widget.NewLabel("Connected clients: 0")
+	am.reloadLogText = widget.NewLabel("")
+	am.reloadLogText.Wrapping = fyne.TextWrapWord
+
 	// Создание контейнера с списком и предпросмотром
 	splitContainer := container.NewHSplit(
 		am.assetList,
-		am.assetPreview,
+		am.previewContainer,
 	)
-	
+
 	// Создание основного контейнера
 	content := container.NewBorder(
 		nil,
-		buttonContainer,
+		container.NewVBox(buttonContainer, am.clientsLabel, am.reloadLogText),
 		nil,
 		nil,
 		splitContainer,
@@ -1169,6 +1837,75 @@ func (am *AssetManager) setupUI() {
 	am.window.Resize(fyne.NewSize(800, 600))
 }
 
+// startLiveReload starts the fsnotify-backed AssetWatcher and the JSON-RPC
+// server that pushes its events to a running game instance (see
+// asset_watcher.go). Either piece failing to start just disables live reload
+// for this session - the Asset Manager window stays usable without it.
+func (am *AssetManager) startLiveReload(port int) {
+	watcher, err := newAssetWatcher(am.assetPath)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("live reload disabled: %w", err), am.window)
+		return
+	}
+	watcher.onEvent = am.logReloadEvent
+
+	server, err := startAssetRPCServer(fmt.Sprintf(":%d", port))
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("asset reload server disabled: %w", err), am.window)
+	} else {
+		server.onClientsChanged = am.setClientCount
+		watcher.rpcServer = server
+	}
+
+	am.watcher = watcher
+	watcher.Start()
+}
+
+// Subscribe returns a channel that receives every AssetEvent published by
+// the live-reload watcher from now on, for callers embedded in the same
+// process. Returns nil if the watcher hasn't started (startLiveReload wasn't
+// called, or failed).
+func (am *AssetManager) Subscribe() <-chan AssetEvent {
+	if am.watcher == nil {
+		return nil
+	}
+	return am.watcher.Subscribe()
+}
+
+// maxReloadLogLines bounds the "recent events" log shown next to the
+// connected-clients indicator.
+const maxReloadLogLines = 10
+
+// logReloadEvent is the AssetWatcher.onEvent hook: it prepends event to the
+// on-screen reload log and refreshes the asset list, since a reload event
+// often means a thumbnail is now stale.
+func (am *AssetManager) logReloadEvent(event AssetEvent) {
+	line := fmt.Sprintf("[%s] %s %s", time.Now().Format("15:04:05"), event.Kind, event.Path)
+
+	am.reloadLogMu.Lock()
+	am.reloadLog = append([]string{line}, am.reloadLog...)
+	if len(am.reloadLog) > maxReloadLogLines {
+		am.reloadLog = am.reloadLog[:maxReloadLogLines]
+	}
+	text := strings.Join(am.reloadLog, "\n")
+	am.reloadLogMu.Unlock()
+
+	if am.reloadLogText != nil {
+		am.reloadLogText.SetText(text)
+	}
+	if am.assetList != nil {
+		am.assetList.Refresh()
+	}
+}
+
+// setClientCount is the assetRPCServer.onClientsChanged hook backing the
+// "Connected clients" indicator.
+func (am *AssetManager) setClientCount(count int) {
+	if am.clientsLabel != nil {
+		am.clientsLabel.SetText(fmt.Sprintf("Connected clients: %d", count))
+	}
+}
+
 // Получение списка файлов ассетов
 func (am *AssetManager) getAssetFiles() []string {
 	// Создание директории ассетов, если она не существует
@@ -1182,87 +1919,96 @@ func (am *AssetManager) getAssetFiles() []string {
 		return []string{}
 	}
 	
-	// Фильтрация файлов
-	var assetFiles []string
+	// Фильтрация файлов: пропускаем каталоги и служебные файлы (кэш превью
+	// в .thumbs/, манифест импорта manifest.json, список скрытых дефолтов
+	// .overrides.json)
+	var diskFiles []string
 	for _, file := range files {
-		if !file.IsDir() {
-			assetFiles = append(assetFiles, file.Name())
+		if file.IsDir() || file.Name() == manifestFileName || file.Name() == overridesFileName {
+			continue
 		}
+		diskFiles = append(diskFiles, file.Name())
 	}
-	
+
+	// Объединение с embedded-дефолтами: пользовательские файлы на диске
+	// переопределяют (shadow) одноимённый дефолт, а скрытые через
+	// deleteAsset дефолты из списка исключаются.
+	assetFiles := mergedAssetNames(am, diskFiles)
+	sort.Strings(assetFiles)
+
 	return assetFiles
 }
 
-// Загрузка предпросмотра ассета
+// textPreviewExtensions - расширения, чей предпросмотр рендерится как
+// подсвеченный текст вместо изображения.
+var textPreviewExtensions = map[string]bool{
+	".json": true,
+	".lua":  true,
+	".txt":  true,
+}
+
+// videoPreviewExtensions и audioPreviewExtensions используют кэшированную
+// миниатюру (первый кадр или waveform PNG), сгенерированную generateThumbnails.
+var videoPreviewExtensions = map[string]bool{".mp4": true, ".webm": true, ".ogv": true}
+var audioPreviewExtensions = map[string]bool{".wav": true, ".ogg": true, ".mp3": true}
+
+// Загрузка предпросмотра ассета: изображения и закэшированные превью
+// видео/аудио рендерятся в am.assetPreview, текстовые/JSON/Lua ассеты -
+// с подсветкой синтаксиса в am.previewText.
 func (am *AssetManager) loadAssetPreview(filename string) {
-	// Полный путь к файлу
-	filePath := filepath.Join(am.assetPath, filename)
-	
-	// Проверка расширения файла
 	ext := strings.ToLower(filepath.Ext(filename))
-	
-	// Загрузка изображения для предпросмотра
-	if ext == ".png" || ext == ".jpg" || ext == ".jpeg" || ext == ".gif" {
-		am.assetPreview.File = filePath
-		am.assetPreview.Refresh()
-	} else {
-		// Для неизображений показываем заглушку
-		am.assetPreview.File = ""
-		am.assetPreview.Refresh()
-	}
-}
 
-// Импорт ассета
-func (am *AssetManager) importAsset() {
-	// Создание диалога для выбора файла
-	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
-		if err != nil {
-			dialog.ShowError(err, am.window)
-			return
-		}
-		
-		if reader == nil {
-			return
-		}
-		
-		// Получение имени файла
-		filename := filepath.Base(reader.URI().String())
-		
-		// Создание директории ассетов, если она не существует
-		if _, err := os.Stat(am.assetPath); os.IsNotExist(err) {
-			os.MkdirAll(am.assetPath, 0755)
-		}
-		
-		// Создание файла для записи
-		file, err := os.Create(filepath.Join(am.assetPath, filename))
+	switch {
+	case ext == ".png" || ext == ".jpg" || ext == ".jpeg" || ext == ".gif":
+		// readAssetBytes prefers a user shadow copy on disk and falls back
+		// to the embedded default, so this also previews un-imported defaults.
+		data, err := am.readAssetBytes(filename)
 		if err != nil {
-			dialog.ShowError(err, am.window)
-			return
+			am.showImagePreview(nil)
+		} else {
+			am.showImagePreview(fyne.NewStaticResource(filename, data))
 		}
-		
-		// Чтение данных из исходного файла
-		data, err := ioutil.ReadAll(reader)
-		if err != nil {
-			dialog.ShowError(err, am.window)
-			return
+	case videoPreviewExtensions[ext] || audioPreviewExtensions[ext]:
+		am.thumbMu.Lock()
+		thumbPath := am.thumbs[filename]
+		am.thumbMu.Unlock()
+		if thumbPath == "" {
+			am.showImagePreview(nil)
+		} else if data, err := ioutil.ReadFile(thumbPath); err == nil {
+			am.showImagePreview(fyne.NewStaticResource(filepath.Base(thumbPath), data))
+		} else {
+			am.showImagePreview(nil)
 		}
-		
-		reader.Close()
-		
-		// Запись данных в новый файл
-		_, err = file.Write(data)
-		if err != nil {
-			dialog.ShowError(err, am.window)
-			return
-		}
-		
-		file.Close()
-		
-		// Обновление списка ассетов
-		am.assetList.Refresh()
-		
-		dialog.ShowInformation("Success", "Asset imported successfully", am.window)
-	}, am.window)
+	case textPreviewExtensions[ext]:
+		am.showTextPreview(filename, ext)
+	default:
+		am.showImagePreview(nil)
+	}
+}
+
+// showImagePreview swaps previewContainer to am.assetPreview, showing
+// resource (nil clears the preview for asset types without one yet).
+func (am *AssetManager) showImagePreview(resource fyne.Resource) {
+	am.assetPreview.Resource = resource
+	am.assetPreview.File = ""
+	am.assetPreview.Refresh()
+	am.previewContainer.Objects = []fyne.CanvasObject{am.assetPreview}
+	am.previewContainer.Refresh()
+}
+
+// showTextPreview swaps previewContainer to am.previewText, populated with a
+// lightweight syntax-highlighted rendering of filename's content - read via
+// readAssetBytes so un-imported embedded defaults preview too.
+func (am *AssetManager) showTextPreview(filename, ext string) {
+	data, err := am.readAssetBytes(filename)
+	if err != nil {
+		am.previewText.Segments = nil
+	} else {
+		am.previewText.Segments = highlightSegments(string(data), ext)
+	}
+	am.previewText.Refresh()
+	am.previewContainer.Objects = []fyne.CanvasObject{container.NewVScroll(am.previewText)}
+	am.previewContainer.Refresh()
 }
 
 // Экспорт ассета
@@ -1292,13 +2038,14 @@ func (am *AssetManager) exportAsset() {
 			return
 		}
 		
-		// Чтение данных из исходного файла
-		data, err := ioutil.ReadFile(filepath.Join(am.assetPath, filename))
+		// Чтение данных из исходного файла: пользовательский файл на диске,
+		// либо, если он не переопределён (shadowed), встроенный дефолт
+		data, err := am.readAssetBytes(filename)
 		if err != nil {
 			dialog.ShowError(err, am.window)
 			return
 		}
-		
+
 		// Запись данных в новый файл
 		_, err = writer.Write(data)
 		if err != nil {
@@ -1330,28 +2077,233 @@ func (am *AssetManager) deleteAsset() {
 	
 	// Подтверждение удаления
 	dialog.ShowConfirm("Confirm Delete", "Are you sure you want to delete this asset?", func(confirmed bool) {
-		if confirmed {
-			// Удаление файла
-			err := os.Remove(filepath.Join(am.assetPath, filename))
-			if err != nil {
+		if !confirmed {
+			return
+		}
+
+		diskPath := filepath.Join(am.assetPath, filename)
+		if _, err := os.Stat(diskPath); err == nil {
+			// Обычный файл пользователя на диске - удаляем как раньше.
+			if err := os.Remove(diskPath); err != nil {
 				dialog.ShowError(err, am.window)
 				return
 			}
-			
-			// Обновление списка ассетов
-			am.assetList.Refresh()
-			
-			// Очистка предпросмотра
-			am.assetPreview.File = ""
-			am.assetPreview.Refresh()
-			
-			dialog.ShowInformation("Success", "Asset deleted successfully", am.window)
+		} else if isEmbeddedDefault(filename) {
+			// Встроенный дефолт нельзя удалить из assets/defaults - помечаем
+			// его скрытым в .overrides.json, чтобы он пропал из списка.
+			if err := am.hideDefault(filename); err != nil {
+				dialog.ShowError(err, am.window)
+				return
+			}
+		} else {
+			dialog.ShowError(fmt.Errorf("asset %q not found", filename), am.window)
+			return
 		}
+
+		am.assetList.Refresh()
+
+		am.assetPreview.File = ""
+		am.assetPreview.Refresh()
+
+		dialog.ShowInformation("Success", "Asset deleted successfully", am.window)
 	}, am.window)
 }
 
-// Запуск инструментов разработки
+// resetAssetToDefault removes any user shadow copy of filename and un-hides
+// it if it had been deleted, reverting it to the shipped embedded default.
+func (am *AssetManager) resetAssetToDefault() {
+	if am.assetList.Selected() < 0 {
+		dialog.ShowInformation("Error", "No asset selected", am.window)
+		return
+	}
+
+	files := am.getAssetFiles()
+	if am.assetList.Selected() >= len(files) {
+		dialog.ShowInformation("Error", "Invalid asset selected", am.window)
+		return
+	}
+	filename := files[am.assetList.Selected()]
+
+	if !isEmbeddedDefault(filename) {
+		dialog.ShowInformation("No default", filename+" has no embedded default to reset to", am.window)
+		return
+	}
+
+	diskPath := filepath.Join(am.assetPath, filename)
+	if _, err := os.Stat(diskPath); err == nil {
+		if err := os.Remove(diskPath); err != nil {
+			dialog.ShowError(err, am.window)
+			return
+		}
+	}
+	if err := am.unhideDefault(filename); err != nil {
+		dialog.ShowError(err, am.window)
+		return
+	}
+
+	am.assetList.Refresh()
+	dialog.ShowInformation("Success", filename+" reset to embedded default", am.window)
+}
+
+// Структура для окна раннера симуляций
+type SimRunnerWindow struct {
+	window     fyne.Window
+	level      *Level
+	runsEntry  *widget.Entry
+	seedEntry  *widget.Entry
+	outputPath string
+	statusLbl  *widget.Label
+}
+
+// Открытие раннера симуляций
+func (dt *DevTools) openSimRunnerWindow() {
+	if dt.simRunner == nil {
+		w := dt.app.NewWindow("Simulation Runner")
+
+		dt.simRunner = &SimRunnerWindow{
+			window:     w,
+			runsEntry:  widget.NewEntry(),
+			seedEntry:  widget.NewEntry(),
+			outputPath: "./sim_traces",
+			statusLbl:  widget.NewLabel("Idle"),
+		}
+		dt.simRunner.runsEntry.SetText("10")
+		dt.simRunner.seedEntry.SetText("1")
+
+		dt.simRunner.setupUI()
+	}
+
+	dt.simRunner.window.Show()
+}
+
+// Настройка UI для раннера симуляций
+func (srw *SimRunnerWindow) setupUI() {
+	loadLevelBtn := widget.NewButton("Load Level (optional)", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+
+			data, err := ioutil.ReadAll(reader)
+			if err != nil {
+				dialog.ShowError(err, srw.window)
+				return
+			}
+
+			var level Level
+			if err := json.Unmarshal(data, &level); err != nil {
+				dialog.ShowError(err, srw.window)
+				return
+			}
+			srw.level = &level
+		}, srw.window)
+	})
+
+	runBtn := widget.NewButton("Run Batch", func() {
+		srw.runBatch()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Runs", srw.runsEntry),
+		widget.NewFormItem("Base Seed", srw.seedEntry),
+	)
+
+	content := container.NewVBox(
+		form,
+		container.NewHBox(loadLevelBtn, runBtn),
+		srw.statusLbl,
+	)
+
+	srw.window.SetContent(content)
+	srw.window.Resize(fyne.NewSize(400, 250))
+}
+
+// runBatch запускает headless-прогон AI-против-AI и пишет один JSONL-трейс
+// на прогон в srw.outputPath/run-<i>.jsonl, читаемый GameAnalyzer напрямую.
+func (srw *SimRunnerWindow) runBatch() {
+	runs, err := strconv.Atoi(srw.runsEntry.Text)
+	if err != nil || runs <= 0 {
+		dialog.ShowError(fmt.Errorf("invalid runs count: %s", srw.runsEntry.Text), srw.window)
+		return
+	}
+
+	seed, err := strconv.ParseInt(srw.seedEntry.Text, 10, 64)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("invalid seed: %s", srw.seedEntry.Text), srw.window)
+		return
+	}
+
+	if err := os.MkdirAll(srw.outputPath, 0755); err != nil {
+		dialog.ShowError(err, srw.window)
+		return
+	}
+
+	settings := GameSettings{GravityScale: 1.0, SpellFrequency: 0.5, AIEnabled: true, AILevel: 2}
+
+	srw.statusLbl.SetText("Running...")
+	results, err := RunBatch(settings, srw.level, seed, runs, func(runIndex int) (io.WriteCloser, error) {
+		return os.Create(filepath.Join(srw.outputPath, fmt.Sprintf("run-%d.jsonl", runIndex)))
+	}, 1000)
+	if err != nil {
+		srw.statusLbl.SetText("Failed")
+		dialog.ShowError(err, srw.window)
+		return
+	}
+
+	srw.statusLbl.SetText(fmt.Sprintf("Done: %d run(s) written to %s", len(results), srw.outputPath))
+}
+
+// Запуск инструментов разработки. Поддерживает подкоманды:
+//
+//	dev_tools gui
+//	dev_tools headless -runs=N -level=foo.json
+//	dev_tools batch -manifest=jobs.json
+//	dev_tools repl
+//	dev_tools serve -addr=:8080
+//	dev_tools config print|paths
+//	dev_tools completion bash|zsh|fish
+//
+// см. cli.go для диспетчеризации и флагов каждой подкоманды.
 func main() {
-	dt := NewDevTools()
-	dt.mainWindow.ShowAndRun()
+	runCLI(os.Args[1:])
+}
+
+// runHeadless выполняет пакетный прогон SimRunner из командной строки, не
+// открывая Fyne-окно, и печатает сводку по каждому прогону.
+func runHeadless(runs int, levelPath string, seed int64, outDir string) {
+	var level *Level
+	if levelPath != "" {
+		data, err := ioutil.ReadFile(levelPath)
+		if err != nil {
+			appLogger.Error("failed to read level", "path", levelPath, "error", err)
+			os.Exit(1)
+		}
+		level = &Level{}
+		if err := json.Unmarshal(data, level); err != nil {
+			appLogger.Error("failed to parse level", "path", levelPath, "error", err)
+			os.Exit(1)
+		}
+		appLogger.Info("level loaded", "path", levelPath)
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		appLogger.Error("failed to create output directory", "path", outDir, "error", err)
+		os.Exit(1)
+	}
+
+	settings := GameSettings{GravityScale: 1.0, SpellFrequency: 0.5, AIEnabled: true, AILevel: 2}
+
+	appLogger.Info("starting headless batch run", "runs", runs, "seed", seed, "out", outDir)
+	results, err := RunBatch(settings, level, seed, runs, func(runIndex int) (io.WriteCloser, error) {
+		return os.Create(filepath.Join(outDir, fmt.Sprintf("run-%d.jsonl", runIndex)))
+	}, 1000)
+	if err != nil {
+		appLogger.Error("batch simulation failed", "error", err)
+		os.Exit(1)
+	}
+
+	for i, result := range results {
+		appLogger.Info("run finished", "run", i, "status", result.GameStatus, "turns", result.CurrentTurn)
+	}
 }
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+)
+
+/**
+ * embedded_assets - ships a set of default assets inside the DevTools binary
+ * itself (via go:embed), so Asset Manager has something to show on a fresh
+ * checkout with no populated ./assets directory. AssetManager.getAssetFiles
+ * presents a merged view of these defaults and whatever the user has
+ * imported: importing a file with the same name shadows the embedded
+ * default on disk (copy-on-write), and "deleting" an embedded default just
+ * records it as hidden in assets/.overrides.json rather than touching the
+ * (read-only, compiled-in) embedded FS.
+ */
+
+//go:embed assets/defaults/*
+var defaultAssetsFS embed.FS
+
+// defaultAssetsRoot is the embedded FS subtree defaultAssetsFS is rooted at.
+const defaultAssetsRoot = "assets/defaults"
+
+// overridesFileName is the sidecar file (inside AssetManager.assetPath)
+// recording which embedded defaults the user has hidden.
+const overridesFileName = ".overrides.json"
+
+// assetOverrides is the on-disk shape of assets/.overrides.json.
+type assetOverrides struct {
+	Hidden []string `json:"hidden"`
+}
+
+// embeddedAssetNames lists every file shipped under assets/defaults/,
+// relative to that root (e.g. "palette.json").
+func embeddedAssetNames() []string {
+	var names []string
+	fs.WalkDir(defaultAssetsFS, defaultAssetsRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(defaultAssetsRoot, path)
+		if err != nil {
+			return nil
+		}
+		names = append(names, rel)
+		return nil
+	})
+	return names
+}
+
+// isEmbeddedDefault reports whether name is one of the shipped defaults.
+func isEmbeddedDefault(name string) bool {
+	for _, n := range embeddedAssetNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (am *AssetManager) overridesPath() string {
+	return filepath.Join(am.assetPath, overridesFileName)
+}
+
+func (am *AssetManager) loadOverrides() assetOverrides {
+	data, err := ioutil.ReadFile(am.overridesPath())
+	if err != nil {
+		return assetOverrides{}
+	}
+	var overrides assetOverrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return assetOverrides{}
+	}
+	return overrides
+}
+
+func (am *AssetManager) saveOverrides(overrides assetOverrides) error {
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode asset overrides: %w", err)
+	}
+	return ioutil.WriteFile(am.overridesPath(), data, 0644)
+}
+
+func (am *AssetManager) isHidden(name string) bool {
+	for _, hidden := range am.loadOverrides().Hidden {
+		if hidden == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hideDefault records name as hidden, so it's no longer presented in the
+// merged asset list even though it still exists in the embedded FS.
+func (am *AssetManager) hideDefault(name string) error {
+	overrides := am.loadOverrides()
+	if am.isHidden(name) {
+		return nil
+	}
+	overrides.Hidden = append(overrides.Hidden, name)
+	return am.saveOverrides(overrides)
+}
+
+// unhideDefault removes name from the hidden list, restoring the embedded
+// default to the merged asset list (used by "Reset to Default").
+func (am *AssetManager) unhideDefault(name string) error {
+	overrides := am.loadOverrides()
+	var kept []string
+	for _, hidden := range overrides.Hidden {
+		if hidden != name {
+			kept = append(kept, hidden)
+		}
+	}
+	overrides.Hidden = kept
+	return am.saveOverrides(overrides)
+}
+
+// mergedAssetNames merges on-disk user files with not-hidden embedded
+// defaults, de-duplicating names the user has shadowed by importing a file
+// with the same name as a default.
+func mergedAssetNames(am *AssetManager, diskFiles []string) []string {
+	seen := make(map[string]bool, len(diskFiles))
+	merged := make([]string, 0, len(diskFiles))
+
+	for _, name := range diskFiles {
+		seen[name] = true
+		merged = append(merged, name)
+	}
+	for _, name := range embeddedAssetNames() {
+		if seen[name] || am.isHidden(name) {
+			continue
+		}
+		merged = append(merged, name)
+	}
+
+	return merged
+}
+
+// readAssetBytes returns the content of an asset by name, preferring a
+// user-imported/shadowing file on disk and falling back to the embedded
+// default - this is what makes import-over-a-default a transparent
+// copy-on-write instead of requiring the user to manage two copies.
+func (am *AssetManager) readAssetBytes(name string) ([]byte, error) {
+	diskPath := filepath.Join(am.assetPath, name)
+	if data, err := ioutil.ReadFile(diskPath); err == nil {
+		return data, nil
+	}
+
+	data, err := defaultAssetsFS.ReadFile(filepath.Join(defaultAssetsRoot, name))
+	if err != nil {
+		return nil, fmt.Errorf("asset %q not found on disk or in embedded defaults", name)
+	}
+	return data, nil
+}
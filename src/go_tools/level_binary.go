@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/**
+ * Компактный бинарный формат уровня ".map", вдохновлённый MAPSTRUCT из Doom2D:
+ * фиксированный заголовок + плотно упакованная таблица блоков. Даёт файлы на
+ * порядок меньше JSON - удобно для турнирных паков и встраивания готовых
+ * уровней в ассет-бандлы игры.
+ *
+ * Layout:
+ *   magic            [4]byte  "STTR"
+ *   version          uint16
+ *   width, height    uint16   размеры поля в тайлах
+ *   gameMode         uint8    0=RACE 1=SURVIVAL 2=PUZZLE
+ *   nameLen          uint16
+ *   name             []byte   UTF-8, nameLen байт
+ *   descriptionLen   uint16
+ *   description      []byte   UTF-8, descriptionLen байт
+ *   winConditionLen  uint16
+ *   winCondition     []byte   UTF-8, winConditionLen байт
+ *   blockCount       uint32
+ *   blocks           blockCount * binaryBlock
+ *
+ * binaryBlock:
+ *   id          uint32
+ *   x, y, w, h  int16   в 1/16 тайла (tile * 16, округлённое)
+ *   r, g, b     uint8
+ *   density     uint8   фиксированная точка, tile-unit * 100, clamp [0,255]
+ *   friction    uint8   fiксированная точка, * 255, clamp [0,255] (friction/restitution в [0,1])
+ *   restitution uint8
+ *   flags       uint8   bit0 = is_static
+ */
+
+const (
+	levelBinaryMagic   = "STTR"
+	levelBinaryVersion = 2
+
+	blockFlagStatic = 1 << 0
+)
+
+var levelBinaryGameModes = []GameMode{GameModeRace, GameModeSurvival, GameModePuzzle}
+
+// writeBinaryString writes s as a uint16 length prefix followed by its UTF-8
+// bytes - the length-prefixed string layout shared by name, description and
+// winCondition. label is only used to name the field in the length-overflow
+// error.
+func writeBinaryString(buf *bytes.Buffer, label, s string) error {
+	data := []byte(s)
+	if len(data) > 0xFFFF {
+		return fmt.Errorf("%s too long to encode (%d bytes)", label, len(data))
+	}
+	binary.Write(buf, binary.LittleEndian, uint16(len(data)))
+	buf.Write(data)
+	return nil
+}
+
+// readBinaryString reads back a string written by writeBinaryString.
+func readBinaryString(r *bytes.Reader, label string) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return "", fmt.Errorf("failed to read %s length: %w", label, err)
+	}
+	data := make([]byte, length)
+	if length > 0 {
+		if _, err := r.Read(data); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", label, err)
+		}
+	}
+	return string(data), nil
+}
+
+// MarshalBinary сериализует уровень в компактный бинарный формат .map.
+func (l Level) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString(levelBinaryMagic)
+	binary.Write(&buf, binary.LittleEndian, uint16(levelBinaryVersion))
+	binary.Write(&buf, binary.LittleEndian, uint16(l.Width))
+	binary.Write(&buf, binary.LittleEndian, uint16(l.Height))
+
+	modeByte, err := gameModeToByte(l.GameMode)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte(modeByte)
+
+	if err := writeBinaryString(&buf, "name", l.Name); err != nil {
+		return nil, err
+	}
+	if err := writeBinaryString(&buf, "description", l.Description); err != nil {
+		return nil, err
+	}
+	if err := writeBinaryString(&buf, "win condition", l.WinCondition); err != nil {
+		return nil, err
+	}
+
+	binary.Write(&buf, binary.LittleEndian, uint32(len(l.Blocks)))
+	for _, block := range l.Blocks {
+		if err := writeBinaryBlock(&buf, block); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary разбирает уровень из формата .map, созданного MarshalBinary.
+func (l *Level) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	magic := make([]byte, len(levelBinaryMagic))
+	if _, err := r.Read(magic); err != nil {
+		return fmt.Errorf("failed to read magic: %w", err)
+	}
+	if string(magic) != levelBinaryMagic {
+		return fmt.Errorf("not a .map level file (bad magic %q)", magic)
+	}
+
+	var version uint16
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return fmt.Errorf("failed to read version: %w", err)
+	}
+	if version != levelBinaryVersion {
+		return fmt.Errorf("unsupported .map version %d", version)
+	}
+
+	var width, height uint16
+	binary.Read(r, binary.LittleEndian, &width)
+	binary.Read(r, binary.LittleEndian, &height)
+
+	var modeByte uint8
+	if err := binary.Read(r, binary.LittleEndian, &modeByte); err != nil {
+		return fmt.Errorf("failed to read game mode: %w", err)
+	}
+	mode, err := byteToGameMode(modeByte)
+	if err != nil {
+		return err
+	}
+
+	name, err := readBinaryString(r, "name")
+	if err != nil {
+		return err
+	}
+	description, err := readBinaryString(r, "description")
+	if err != nil {
+		return err
+	}
+	winCondition, err := readBinaryString(r, "win condition")
+	if err != nil {
+		return err
+	}
+
+	var blockCount uint32
+	if err := binary.Read(r, binary.LittleEndian, &blockCount); err != nil {
+		return fmt.Errorf("failed to read block count: %w", err)
+	}
+
+	blocks := make([]Block, blockCount)
+	for i := range blocks {
+		block, err := readBinaryBlock(r)
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", i, err)
+		}
+		blocks[i] = block
+	}
+
+	l.Width = int(width)
+	l.Height = int(height)
+	l.GameMode = mode
+	l.Name = name
+	l.Description = description
+	l.WinCondition = winCondition
+	l.Blocks = blocks
+	return nil
+}
+
+// tileFixedPoint16 - единицы кодирования координат/размеров: 1/16 тайла.
+const tileFixedPoint16 = 16.0
+
+func writeBinaryBlock(buf *bytes.Buffer, b Block) error {
+	binary.Write(buf, binary.LittleEndian, uint32(b.ID))
+	binary.Write(buf, binary.LittleEndian, int16(b.X*tileFixedPoint16))
+	binary.Write(buf, binary.LittleEndian, int16(b.Y*tileFixedPoint16))
+	binary.Write(buf, binary.LittleEndian, int16(b.Width*tileFixedPoint16))
+	binary.Write(buf, binary.LittleEndian, int16(b.Height*tileFixedPoint16))
+
+	r, g, bl, err := parseHexColor(b.Color)
+	if err != nil {
+		return fmt.Errorf("block %d: %w", b.ID, err)
+	}
+	buf.WriteByte(r)
+	buf.WriteByte(g)
+	buf.WriteByte(bl)
+
+	buf.WriteByte(floatToFixed8(b.Density, 100))
+	buf.WriteByte(floatToFixed8(b.Friction, 255))
+	buf.WriteByte(floatToFixed8(b.Restitution, 255))
+
+	var flags uint8
+	if b.IsStatic {
+		flags |= blockFlagStatic
+	}
+	buf.WriteByte(flags)
+
+	return nil
+}
+
+func readBinaryBlock(r *bytes.Reader) (Block, error) {
+	var id uint32
+	var x, y, w, h int16
+	var rgb [3]byte
+	var density, friction, restitution, flags uint8
+
+	for _, field := range []any{&id, &x, &y, &w, &h} {
+		if err := binary.Read(r, binary.LittleEndian, field); err != nil {
+			return Block{}, err
+		}
+	}
+	if _, err := r.Read(rgb[:]); err != nil {
+		return Block{}, err
+	}
+	for _, field := range []*uint8{&density, &friction, &restitution, &flags} {
+		if err := binary.Read(r, binary.LittleEndian, field); err != nil {
+			return Block{}, err
+		}
+	}
+
+	return Block{
+		ID:          int(id),
+		X:           float64(x) / tileFixedPoint16,
+		Y:           float64(y) / tileFixedPoint16,
+		Width:       float64(w) / tileFixedPoint16,
+		Height:      float64(h) / tileFixedPoint16,
+		Color:       fmt.Sprintf("#%02X%02X%02X", rgb[0], rgb[1], rgb[2]),
+		Density:     fixed8ToFloat(density, 100),
+		Friction:    fixed8ToFloat(friction, 255),
+		Restitution: fixed8ToFloat(restitution, 255),
+		IsStatic:    flags&blockFlagStatic != 0,
+	}, nil
+}
+
+func floatToFixed8(v float64, scale float64) uint8 {
+	fixed := v * scale
+	if fixed < 0 {
+		fixed = 0
+	}
+	if fixed > 255 {
+		fixed = 255
+	}
+	return uint8(fixed)
+}
+
+func fixed8ToFloat(v uint8, scale float64) float64 {
+	return float64(v) / scale
+}
+
+func parseHexColor(s string) (r, g, b uint8, err error) {
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return r, g, b, nil
+}
+
+func gameModeToByte(mode GameMode) (byte, error) {
+	for i, m := range levelBinaryGameModes {
+		if m == mode {
+			return byte(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown game mode %q", mode)
+}
+
+func byteToGameMode(b byte) (GameMode, error) {
+	if int(b) >= len(levelBinaryGameModes) {
+		return "", fmt.Errorf("unknown game mode byte %d", b)
+	}
+	return levelBinaryGameModes[b], nil
+}
+
+// isLevelBinaryFormat проверяет магические байты ".map"-формата, чтобы
+// LevelEditor мог выбрать между binary.Unmarshal и json.Unmarshal при загрузке.
+func isLevelBinaryFormat(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(levelBinaryMagic))
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+/**
+ * Интерактивное редактирование холста LevelEditor: клик для выделения,
+ * drag для перемещения с привязкой к сетке 30px, shift-клик для
+ * мультивыделения, правый клик для контекстного меню (rotate/duplicate/
+ * toggle static), и ограниченный кольцевой буфер undo/redo поверх []Block.
+ * Раньше le.grid был статическим canvas.Raster: addBlock всегда ставил
+ * блок в (5,10), а selectedBlock никогда не назначался ни одним обработчиком.
+ */
+
+// gridPixelsPerTile - сколько экранных пикселей занимает один тайл на сетке
+// редактора (совпадает с шагом сетки в раскраске le.grid).
+const gridPixelsPerTile = 30.0
+
+// maxUndoHistory - глубина кольцевого буфера undo/redo.
+const maxUndoHistory = 50
+
+// editableGrid оборачивает le.grid в виджет, реагирующий на клики, drag и
+// правую кнопку мыши - canvas.Raster сам по себе не реализует ни одного
+// из этих интерфейсов.
+type editableGrid struct {
+	widget.BaseWidget
+	editor *LevelEditor
+
+	dragging    bool
+	dragBlockID int
+}
+
+func newEditableGrid(editor *LevelEditor) *editableGrid {
+	g := &editableGrid{editor: editor}
+	g.ExtendBaseWidget(g)
+	return g
+}
+
+func (g *editableGrid) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(g.editor.grid)
+}
+
+// MouseDown обрабатывает левый (выделение, с shift - мультивыделение) и
+// правый (контекстное меню) клик. Реализует desktop.Mouseable.
+func (g *editableGrid) MouseDown(ev *desktop.MouseEvent) {
+	switch ev.Button {
+	case desktop.MouseButtonSecondary:
+		g.editor.showContextMenu(ev.AbsolutePosition, ev.Position)
+	case desktop.MouseButtonPrimary:
+		shift := ev.Modifier&desktop.ShiftModifier != 0
+		block := g.editor.blockAtPixel(ev.Position)
+		g.editor.selectBlock(block, shift)
+		if block != nil {
+			g.editor.pushHistory()
+			g.dragging = true
+			g.dragBlockID = block.ID
+		}
+	}
+}
+
+// MouseUp завершает drag, если он был в процессе. Реализует desktop.Mouseable.
+func (g *editableGrid) MouseUp(*desktop.MouseEvent) {
+	if g.dragging {
+		g.editor.commitMove()
+	}
+	g.dragging = false
+}
+
+// Dragged перемещает выбранный блок под курсором, со снапом к сетке.
+// Реализует fyne.Draggable.
+func (g *editableGrid) Dragged(ev *fyne.DragEvent) {
+	if !g.dragging {
+		return
+	}
+	// ev.Dragged - смещение с прошлого события drag (обычно несколько
+	// пикселей), поэтому блоки сдвигаются на целый тайл каждый раз, когда
+	// курсор пересекает линию сетки.
+	prevX := float64(ev.Position.X - ev.Dragged.DX)
+	prevY := float64(ev.Position.Y - ev.Dragged.DY)
+	dxTiles := math.Floor(float64(ev.Position.X)/gridPixelsPerTile) - math.Floor(prevX/gridPixelsPerTile)
+	dyTiles := math.Floor(float64(ev.Position.Y)/gridPixelsPerTile) - math.Floor(prevY/gridPixelsPerTile)
+	if dxTiles != 0 || dyTiles != 0 {
+		g.editor.dragSelectedBy(dxTiles, dyTiles)
+	}
+}
+
+// DragEnd фиксирует перемещение в истории undo/redo. Реализует fyne.Draggable.
+func (g *editableGrid) DragEnd() {
+	if g.dragging {
+		g.editor.commitMove()
+	}
+	g.dragging = false
+}
+
+// blockAtPixel находит верхний (последний в списке) блок под точкой экрана,
+// или nil, если клик пришёлся мимо всех блоков.
+func (le *LevelEditor) blockAtPixel(pos fyne.Position) *Block {
+	x := float64(pos.X) / gridPixelsPerTile
+	y := float64(pos.Y) / gridPixelsPerTile
+
+	for i := len(le.blocks) - 1; i >= 0; i-- {
+		b := le.blocks[i]
+		if x >= b.X && x < b.X+b.Width && y >= b.Y && y < b.Y+b.Height {
+			return &le.blocks[i]
+		}
+	}
+	return nil
+}
+
+// selectBlock устанавливает основное выделение (используется blockProperties)
+// и, если addToSelection выставлен (shift-клик), добавляет блок к
+// мультивыделению вместо его замены.
+func (le *LevelEditor) selectBlock(block *Block, addToSelection bool) {
+	if le.selectedIDs == nil {
+		le.selectedIDs = make(map[int]bool)
+	}
+
+	if block == nil {
+		if !addToSelection {
+			le.selectedIDs = make(map[int]bool)
+			le.selectedBlock = nil
+		}
+		le.grid.Refresh()
+		return
+	}
+
+	if !addToSelection {
+		le.selectedIDs = make(map[int]bool)
+	}
+	le.selectedIDs[block.ID] = true
+	le.selectedBlock = block
+
+	le.populatePropertiesForm()
+	le.grid.Refresh()
+}
+
+// populatePropertiesForm заполняет поля blockProperties значениями текущего
+// основного выделения, чтобы их можно было отредактировать и применить.
+func (le *LevelEditor) populatePropertiesForm() {
+	if le.selectedBlock == nil || le.propX == nil {
+		return
+	}
+	b := le.selectedBlock
+	le.propX.SetText(fmt.Sprintf("%.2f", b.X))
+	le.propY.SetText(fmt.Sprintf("%.2f", b.Y))
+	le.propWidth.SetText(fmt.Sprintf("%.2f", b.Width))
+	le.propHeight.SetText(fmt.Sprintf("%.2f", b.Height))
+	le.propColor.SetText(b.Color)
+	le.propStatic.SetChecked(b.IsStatic)
+}
+
+// applyPropertiesForm записывает значения формы обратно в le.blocks для
+// текущего основного выделения и перерисовывает сетку.
+func (le *LevelEditor) applyPropertiesForm() {
+	if le.selectedBlock == nil {
+		return
+	}
+
+	index := le.indexOfBlock(le.selectedBlock.ID)
+	if index < 0 {
+		return
+	}
+
+	le.pushHistory()
+
+	if v, err := strconv.ParseFloat(le.propX.Text, 64); err == nil {
+		le.blocks[index].X = v
+	}
+	if v, err := strconv.ParseFloat(le.propY.Text, 64); err == nil {
+		le.blocks[index].Y = v
+	}
+	if v, err := strconv.ParseFloat(le.propWidth.Text, 64); err == nil {
+		le.blocks[index].Width = v
+	}
+	if v, err := strconv.ParseFloat(le.propHeight.Text, 64); err == nil {
+		le.blocks[index].Height = v
+	}
+	le.blocks[index].Color = le.propColor.Text
+	le.blocks[index].IsStatic = le.propStatic.Checked
+
+	le.selectedBlock = &le.blocks[index]
+	le.grid.Refresh()
+}
+
+// indexOfBlock находит позицию блока с данным ID в le.blocks, или -1.
+func (le *LevelEditor) indexOfBlock(id int) int {
+	for i, b := range le.blocks {
+		if b.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// dragSelectedBy сдвигает все блоки текущего мультивыделения на (dxTiles,
+// dyTiles) тайлов, округляя итоговую позицию до ближайшего тайла - drag
+// "снапится" к сетке, а не двигается попиксельно.
+func (le *LevelEditor) dragSelectedBy(dxTiles, dyTiles float64) {
+	for id := range le.selectedIDs {
+		index := le.indexOfBlock(id)
+		if index < 0 {
+			continue
+		}
+		le.blocks[index].X = snapToGrid(le.blocks[index].X + dxTiles)
+		le.blocks[index].Y = snapToGrid(le.blocks[index].Y + dyTiles)
+	}
+	le.grid.Refresh()
+}
+
+// snapToGrid округляет тайловую координату до ближайшего целого тайла.
+func snapToGrid(v float64) float64 {
+	return float64(int(v + 0.5))
+}
+
+// commitMove обновляет форму свойств после завершения drag-перемещения. Сам
+// снимок для undo/redo снимается в MouseDown, до первой мутации позиции -
+// как и везде в этом файле, pushHistory вызывается перед изменением, а не
+// после.
+func (le *LevelEditor) commitMove() {
+	le.populatePropertiesForm()
+}
+
+// showContextMenu открывает всплывающее меню для блока под курсором:
+// Rotate 90°, Duplicate, Toggle Static.
+func (le *LevelEditor) showContextMenu(absolutePos, localPos fyne.Position) {
+	block := le.blockAtPixel(localPos)
+	if block == nil {
+		return
+	}
+	le.selectBlock(block, false)
+
+	menu := fyne.NewMenu("",
+		fyne.NewMenuItem("Rotate 90°", func() { le.rotateSelected() }),
+		fyne.NewMenuItem("Duplicate", func() { le.duplicateSelected() }),
+		fyne.NewMenuItem("Toggle Static", func() { le.toggleStaticSelected() }),
+	)
+	widget.ShowPopUpMenuAtPosition(menu, le.window.Canvas(), absolutePos)
+}
+
+// rotateSelected поворачивает выделенный блок на 90 градусов, меняя местами
+// его ширину и высоту.
+func (le *LevelEditor) rotateSelected() {
+	if le.selectedBlock == nil {
+		return
+	}
+	index := le.indexOfBlock(le.selectedBlock.ID)
+	if index < 0 {
+		return
+	}
+
+	le.pushHistory()
+	le.blocks[index].Rotation = float64(int(le.blocks[index].Rotation+90) % 360)
+	le.blocks[index].Width, le.blocks[index].Height = le.blocks[index].Height, le.blocks[index].Width
+	le.selectedBlock = &le.blocks[index]
+	le.grid.Refresh()
+}
+
+// duplicateSelected клонирует выделенный блок со смещением на один тайл.
+func (le *LevelEditor) duplicateSelected() {
+	if le.selectedBlock == nil {
+		return
+	}
+
+	le.pushHistory()
+	clone := *le.selectedBlock
+	clone.ID = len(le.blocks)
+	clone.X++
+	clone.Y++
+	le.blocks = append(le.blocks, clone)
+	le.selectBlock(&le.blocks[len(le.blocks)-1], false)
+	le.grid.Refresh()
+}
+
+// toggleStaticSelected переключает IsStatic у выделенного блока.
+func (le *LevelEditor) toggleStaticSelected() {
+	if le.selectedBlock == nil {
+		return
+	}
+	index := le.indexOfBlock(le.selectedBlock.ID)
+	if index < 0 {
+		return
+	}
+
+	le.pushHistory()
+	le.blocks[index].IsStatic = !le.blocks[index].IsStatic
+	le.selectedBlock = &le.blocks[index]
+	le.populatePropertiesForm()
+	le.grid.Refresh()
+}
+
+// pushHistory снимает снимок текущих le.blocks и кладёт его в кольцевой
+// буфер undo/redo, обрезая любую "будущую" историю после текущей позиции
+// (как обычно работает undo - новое действие после undo стирает redo-хвост).
+func (le *LevelEditor) pushHistory() {
+	snapshot := append([]Block(nil), le.blocks...)
+
+	le.history = append(le.history[:le.historyIndex], snapshot)
+	if len(le.history) > maxUndoHistory {
+		le.history = le.history[len(le.history)-maxUndoHistory:]
+	}
+	le.historyIndex = len(le.history)
+}
+
+// undo откатывает le.blocks к состоянию до последней зафиксированной мутации.
+func (le *LevelEditor) undo() {
+	if le.historyIndex == 0 {
+		return
+	}
+	// Первый undo после мутации сохраняет текущее состояние в конец истории,
+	// чтобы redo мог к нему вернуться.
+	if le.historyIndex == len(le.history) {
+		le.history = append(le.history, append([]Block(nil), le.blocks...))
+	}
+	le.historyIndex--
+	le.blocks = append([]Block(nil), le.history[le.historyIndex]...)
+	le.selectedBlock = nil
+	le.selectedIDs = make(map[int]bool)
+	le.grid.Refresh()
+}
+
+// redo повторно применяет состояние, отменённое последним undo.
+func (le *LevelEditor) redo() {
+	if le.historyIndex+1 >= len(le.history) {
+		return
+	}
+	le.historyIndex++
+	le.blocks = append([]Block(nil), le.history[le.historyIndex]...)
+	le.selectedBlock = nil
+	le.selectedIDs = make(map[int]bool)
+	le.grid.Refresh()
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+/**
+ * repl - interactive shell for the dev_tools CLI. The old interactive mode
+ * (in the now-removed main.go) read single bare words with fmt.Scanln - no
+ * spaces, no history, no arguments. This replaces it with a REPL that reads
+ * full lines, accepts arguments ("headless --runs=10 --seed=2"), keeps a
+ * history file across sessions, and supports "!"-prefixed shell escapes.
+ *
+ * NOTE: the request this implements asked for chzyer/readline or
+ * peterh/liner, specifically for arrow-key history recall and tab
+ * completion - neither is achievable with the standard library alone (both
+ * need raw terminal mode), and this tree has no vendored dependencies to
+ * draw on (see cli.go's note on main.go). What's below is the honest subset
+ * buildable on bufio: full-line input with arguments, a persisted history
+ * *file* (no in-session arrow-key recall), and shell escapes. Pipe-style
+ * command chaining ("generate | analyze") is also out of scope - this
+ * binary doesn't have a "generate" stage independent of a full simulate run
+ * to pipe from.
+ */
+
+// replHistoryFile is where command history is appended/loaded from, relative
+// to the user's home directory.
+const replHistoryFile = ".tetris-tools/history"
+
+// runReplCommand starts the interactive shell. It has no flags of its own
+// today but takes a FlagSet for symmetry with the other subcommands.
+func runReplCommand(args []string) {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	fs.Parse(args)
+
+	historyPath := replHistoryPath()
+	history := loadReplHistory(historyPath)
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Println(tr("repl.welcome"))
+
+	for {
+		fmt.Print("> ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break // EOF (piped input exhausted, or stdin closed)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		history = append(history, line)
+		appendReplHistory(historyPath, line)
+
+		if shouldExitRepl(line) {
+			break
+		}
+
+		dispatchReplLine(line)
+	}
+
+	_ = history // kept in memory in case a future recall command wants it
+}
+
+func shouldExitRepl(line string) bool {
+	return line == "exit" || line == "quit"
+}
+
+// dispatchReplLine runs one REPL command line: a "!"-prefixed shell escape,
+// "help", or a dev_tools subcommand name followed by its own flags.
+func dispatchReplLine(line string) {
+	if strings.HasPrefix(line, "!") {
+		runShellEscape(strings.TrimPrefix(line, "!"))
+		return
+	}
+
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "help":
+		printReplHelp()
+	case "gui":
+		runGUICommand(fields[1:])
+	case "headless":
+		runReplHeadless(fields[1:])
+	case "batch":
+		runReplBatch(fields[1:])
+	default:
+		fmt.Println(tr("repl.unknown_command", fields[0]))
+	}
+}
+
+func printReplHelp() {
+	fmt.Println(tr("repl.help_header"))
+	fmt.Println(tr("repl.help_headless"))
+	fmt.Println(tr("repl.help_batch"))
+	fmt.Println(tr("repl.help_gui"))
+	fmt.Println(tr("repl.help_shell_escape"))
+	fmt.Println(tr("repl.help_help"))
+	fmt.Println(tr("repl.help_exit"))
+}
+
+// runReplHeadless and runReplBatch mirror runHeadlessCommand/runBatchCommand
+// but parse with flag.ContinueOnError, so a bad flag, manifest or batch run
+// is reported without exiting the shell. runReplHeadless calls runHeadless,
+// which still exits the whole process if it can't read or parse the level,
+// create the output directory, or run a simulation.
+func runReplHeadless(args []string) {
+	fs := flag.NewFlagSet("headless", flag.ContinueOnError)
+	runs := fs.Int("runs", 1, "Number of simulation runs")
+	levelPath := fs.String("level", "", "Level JSON file to seed simulations with")
+	seed := fs.Int64("seed", 1, "Base RNG seed")
+	outDir := fs.String("out", "./sim_traces", "Directory to write run-<i>.jsonl traces to")
+	if err := fs.Parse(args); err != nil {
+		return
+	}
+	runHeadless(*runs, *levelPath, *seed, *outDir)
+}
+
+func runReplBatch(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	manifestPath := fs.String("manifest", "", "Path to the batch manifest JSON file")
+	if err := fs.Parse(args); err != nil {
+		return
+	}
+	if *manifestPath == "" {
+		fmt.Println(tr("repl.batch_usage"))
+		return
+	}
+
+	manifest, err := loadBatchManifest(*manifestPath)
+	if err != nil {
+		fmt.Println(tr("cli.batch_load_manifest_failed", err))
+		return
+	}
+	results, err := runBatchManifest(manifest)
+	if err != nil {
+		fmt.Println(tr("cli.batch_run_failed", err))
+		return
+	}
+	printBatchSummary(results)
+}
+
+// runShellEscape runs command through the user's shell, with its own
+// stdin/stdout/stderr so interactive commands (e.g. "!less trace.jsonl")
+// behave normally.
+func runShellEscape(command string) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Println(tr("repl.shell_failed", err))
+	}
+}
+
+// replHistoryPath resolves replHistoryFile under the user's home directory,
+// falling back to the relative path if the home directory can't be
+// determined.
+func replHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return replHistoryFile
+	}
+	return filepath.Join(home, replHistoryFile)
+}
+
+// loadReplHistory reads previously-persisted command lines, oldest first.
+// A missing history file just means no prior history, not an error.
+func loadReplHistory(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var history []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+	return history
+}
+
+// appendReplHistory appends one command line to the history file, creating
+// its parent directory and the file itself on first use.
+func appendReplHistory(path, line string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	pprofruntime "runtime/pprof"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+ * serve - runs dev_tools as a long-lived HTTP service: pprof's standard
+ * profiling endpoints, a hand-rolled Prometheus-text /metrics endpoint, and
+ * a small REST API wrapping the subsystems that exist in this tree (level
+ * creation, trace analysis, and CPU profiling of a simulation run).
+ *
+ * NOTE: the request this implements asked for VictoriaMetrics/metrics or
+ * prometheus/client_golang for /metrics, and REST endpoints named after a
+ * generator/analyzer/profiler/editor split this repo doesn't have (see
+ * cli.go's note on main.go). Neither metrics library is a dependency in
+ * go.mod, so the counters/histogram/gauge below are a minimal hand-rolled
+ * subset of the Prometheus text exposition format - enough for an existing
+ * scrape config to read, not a general metrics library. The REST endpoints
+ * wrap the real equivalents: /generate writes a blank Level (LevelEditor's
+ * newLevel, without the GUI), /analyze wraps analyzeBatchJob, /profile wraps
+ * a runtime/pprof CPU profile of a batch of headless runs, and /levels/{id}
+ * serves a previously generated level back.
+ */
+
+// servedLevelsDir is where /generate writes levels and /levels/{id} reads
+// them back from, relative to the server's working directory.
+const servedLevelsDir = "./served_levels"
+
+// runServeCommand parses serve's own flags and blocks running the HTTP
+// server until the process is killed.
+func runServeCommand(args []string) {
+	cfg, origins := loadLayeredConfig()
+
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", cfg.ServeAddr, "Address to listen on")
+	levelsDir := fs.String("levels-dir", servedLevelsDir, "Directory to read/write generated levels in")
+	fs.Parse(args)
+
+	fieldByFlag := map[string]string{"addr": "serve_addr"}
+	applyConfigFlagOverrides(fs, origins, fieldByFlag)
+	logEffectiveConfig("serve", origins, fieldByFlag)
+
+	if err := os.MkdirAll(*levelsDir, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, tr("serve.create_levels_dir_failed", err))
+		os.Exit(1)
+	}
+
+	srv := &devToolsServer{levelsDir: *levelsDir, metrics: newServeMetrics()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/metrics", srv.metrics)
+	mux.HandleFunc("/generate", srv.handleGenerate)
+	mux.HandleFunc("/analyze", srv.handleAnalyze)
+	mux.HandleFunc("/profile", srv.handleProfile)
+	mux.HandleFunc("/levels/", srv.handleGetLevel)
+
+	appLogger.Info(tr("serve.listening"), "addr", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Fprintln(os.Stderr, tr("serve.listen_failed", err))
+		os.Exit(1)
+	}
+}
+
+// devToolsServer holds the state REST handlers share: where generated
+// levels live and the metrics they report against.
+type devToolsServer struct {
+	levelsDir string
+	metrics   *serveMetrics
+}
+
+// handleGenerate creates a new blank level (the same starting point as
+// LevelEditor.newLevel, but named after its ID), saves it under levelsDir
+// keyed by an incrementing ID, and returns its ID.
+func (s *devToolsServer) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, tr("serve.method_not_allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := fmt.Sprintf("level-%d", time.Now().UnixNano())
+	level := Level{
+		Name:         id,
+		Description:  "A new level",
+		GameMode:     GameModeRace,
+		Blocks:       []Block{},
+		WinCondition: "height >= 15",
+	}
+
+	data, err := json.MarshalIndent(level, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(s.levelsDir, id+".json"), data, 0644); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.metrics.levelsGenerated.Add(1)
+	writeJSON(w, map[string]string{"id": id})
+}
+
+// handleAnalyze wraps analyzeBatchJob: the request body names a trace file,
+// the response is the same summary JSON a batch "analyze" job writes.
+func (s *devToolsServer) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, tr("serve.method_not_allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		TracePath string `json:"trace_path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	summaryPath := filepath.Join(s.levelsDir, fmt.Sprintf("analyze-%d.summary.json", time.Now().UnixNano()))
+	err := analyzeBatchJob(req.TracePath, summaryPath)
+	s.metrics.analyzeDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// handleProfile runs a short headless simulation batch under
+// runtime/pprof.StartCPUProfile and returns the raw profile, so an operator
+// can capture a CPU profile of simulation load on demand rather than only
+// through /debug/pprof/profile (which profiles the server itself, not a
+// simulation run it's asked to perform).
+func (s *devToolsServer) handleProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, tr("serve.method_not_allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Runs int   `json:"runs"`
+		Seed int64 `json:"seed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength != 0 {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Runs <= 0 {
+		req.Runs = 1
+	}
+
+	profilePath := filepath.Join(s.levelsDir, fmt.Sprintf("profile-%d.pprof", time.Now().UnixNano()))
+	profileFile, err := os.Create(profilePath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer profileFile.Close()
+
+	if err := pprofruntime.StartCPUProfile(profileFile); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	outDir := filepath.Join(s.levelsDir, fmt.Sprintf("profile-%d-traces", time.Now().UnixNano()))
+	runHeadless(req.Runs, "", req.Seed, outDir)
+	pprofruntime.StopCPUProfile()
+
+	data, err := os.ReadFile(profilePath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+}
+
+// handleGetLevel serves back a level previously written by /generate.
+func (s *devToolsServer) handleGetLevel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, tr("serve.method_not_allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Path[len("/levels/"):]
+	if id == "" {
+		http.Error(w, tr("serve.missing_level_id"), http.StatusBadRequest)
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.levelsDir, id+".json"))
+	if err != nil {
+		http.Error(w, tr("serve.level_not_found"), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// --- minimal Prometheus text exposition ------------------------------------
+
+// serveMetrics is the fixed set of counters/histograms/gauges the request
+// asked for: levels generated, analyze duration, and (via SetEditorSessions)
+// a gauge for open editor sessions.
+type serveMetrics struct {
+	levelsGenerated *serveCounter
+	analyzeDuration *serveHistogram
+	editorSessions  *serveGauge
+}
+
+func newServeMetrics() *serveMetrics {
+	return &serveMetrics{
+		levelsGenerated: &serveCounter{},
+		analyzeDuration: newServeHistogram([]float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10}),
+		editorSessions:  &serveGauge{},
+	}
+}
+
+// ServeHTTP renders every metric in Prometheus text exposition format.
+func (m *serveMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tetris_levels_generated_total Levels generated via POST /generate")
+	fmt.Fprintln(w, "# TYPE tetris_levels_generated_total counter")
+	fmt.Fprintf(w, "tetris_levels_generated_total %d\n", m.levelsGenerated.Load())
+
+	fmt.Fprintln(w, "# HELP tetris_editor_session_count Open LevelEditor sessions")
+	fmt.Fprintln(w, "# TYPE tetris_editor_session_count gauge")
+	fmt.Fprintf(w, "tetris_editor_session_count %d\n", m.editorSessions.Load())
+
+	fmt.Fprintln(w, "# HELP tetris_analyze_duration_seconds Duration of POST /analyze requests")
+	fmt.Fprintln(w, "# TYPE tetris_analyze_duration_seconds histogram")
+	m.analyzeDuration.WriteTo(w, "tetris_analyze_duration_seconds")
+}
+
+// SetEditorSessions reports the number of currently open editor sessions.
+// No code in this tree calls it, so tetris_editor_session_count reports 0.
+func (m *serveMetrics) SetEditorSessions(count int) {
+	m.editorSessions.Store(int64(count))
+}
+
+type serveCounter struct{ value int64 }
+
+func (c *serveCounter) Add(delta int64) { atomic.AddInt64(&c.value, delta) }
+func (c *serveCounter) Load() int64     { return atomic.LoadInt64(&c.value) }
+
+type serveGauge struct{ value int64 }
+
+func (g *serveGauge) Store(v int64) { atomic.StoreInt64(&g.value, v) }
+func (g *serveGauge) Load() int64   { return atomic.LoadInt64(&g.value) }
+
+// serveHistogram is a fixed-bucket cumulative histogram, the shape
+// Prometheus text exposition expects ("le" buckets with cumulative counts).
+type serveHistogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64 // counts[i] = observations <= buckets[i]
+	sum     float64
+	total   uint64
+}
+
+func newServeHistogram(buckets []float64) *serveHistogram {
+	return &serveHistogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *serveHistogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sum += v
+	h.total++
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+}
+
+func (h *serveHistogram) WriteTo(w http.ResponseWriter, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bound := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.total)
+	fmt.Fprintf(w, "%s_sum %s\n", name, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.total)
+}
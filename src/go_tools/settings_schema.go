@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/**
+ * settings_schema - versioned schema handling for GameSettings.
+ * SettingsEditor.loadSettings used to json.Unmarshal straight into
+ * GameSettings, silently accepting whatever was in the file (wrong game
+ * mode strings, out-of-range sliders, files from an older version of the
+ * tool with different field names). Loading now goes through a generic
+ * map[string]any so a registry of migrations can walk an old file up to
+ * currentSettingsSchemaVersion, a validator can enforce field bounds, and a
+ * JSON Schema can be exported for external tools to validate against.
+ */
+
+// currentSettingsSchemaVersion is the schema version written by this build
+// of DevTools. Bump it (and add a migration keyed by the version it
+// replaces) whenever GameSettings' on-disk shape changes.
+const currentSettingsSchemaVersion = 2
+
+// settingsMigration transforms a settings file from one schema version to
+// the next, keyed by the *source* version it accepts.
+type settingsMigration func(map[string]interface{}) (map[string]interface{}, error)
+
+// settingsMigrations is the migration registry. Files with no
+// "schema_version" field at all are treated as version 1 (pre-dates the
+// field's introduction).
+var settingsMigrations = map[int]settingsMigration{
+	1: migrateSettingsV1ToV2,
+}
+
+// migrateSettingsV1ToV2 renames the legacy "ai_difficulty" field (a free-form
+// string like "easy"/"medium"/"hard") to the numeric "ai_level" scale
+// introduced in schema v2, mapping unrecognized strings to level 2. A file
+// without "ai_difficulty" gets no "ai_level" from this migration.
+func migrateSettingsV1ToV2(raw map[string]interface{}) (map[string]interface{}, error) {
+	if legacy, ok := raw["ai_difficulty"]; ok {
+		level := 2
+		switch v := legacy.(type) {
+		case string:
+			switch v {
+			case "easy":
+				level = 1
+			case "medium":
+				level = 2
+			case "hard":
+				level = 3
+			}
+		case float64:
+			level = int(v)
+		}
+		raw["ai_level"] = float64(level)
+		delete(raw, "ai_difficulty")
+	}
+
+	raw["schema_version"] = float64(2)
+	return raw, nil
+}
+
+// migrateSettingsMap walks raw forward through settingsMigrations from its
+// recorded "schema_version" (or 1, if absent) up to
+// currentSettingsSchemaVersion.
+func migrateSettingsMap(raw map[string]interface{}) (map[string]interface{}, error) {
+	version := 1
+	if v, ok := raw["schema_version"].(float64); ok {
+		version = int(v)
+	}
+
+	for version < currentSettingsSchemaVersion {
+		migrate, ok := settingsMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration registered from settings schema version %d", version)
+		}
+
+		migrated, err := migrate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate settings from schema version %d: %w", version, err)
+		}
+		raw = migrated
+
+		next, ok := raw["schema_version"].(float64)
+		if !ok || int(next) <= version {
+			return nil, fmt.Errorf("migration from schema version %d did not advance schema_version", version)
+		}
+		version = int(next)
+	}
+
+	return raw, nil
+}
+
+// validGameModes is the enum accepted by GameSettings.DefaultGameMode.
+var validGameModes = []GameMode{GameModeRace, GameModeSurvival, GameModePuzzle}
+
+// validAILevels is the enum accepted by GameSettings.AILevel.
+var validAILevels = []int{1, 2, 3}
+
+const (
+	minGravityScale = 0.1
+	maxGravityScale = 10.0
+)
+
+// settingsIssue records one field that failed validation and the value it
+// was clamped/defaulted to, so the diff dialog can show the user exactly
+// what changed before they accept it.
+type settingsIssue struct {
+	Field    string
+	Original string
+	Clamped  string
+}
+
+// validateAndClampSettings checks settings against the field bounds the
+// schema documents (GravityScale in [0.1, 10.0], AILevel in {1,2,3},
+// DefaultGameMode in the enum set) and returns a corrected copy plus a
+// human-readable list of anything it had to fix.
+func validateAndClampSettings(settings GameSettings) (GameSettings, []settingsIssue) {
+	var issues []settingsIssue
+	clamped := settings
+
+	if clamped.GravityScale < minGravityScale || clamped.GravityScale > maxGravityScale {
+		original := fmt.Sprintf("%.2f", clamped.GravityScale)
+		clamped.GravityScale = clampFloat(clamped.GravityScale, minGravityScale, maxGravityScale)
+		issues = append(issues, settingsIssue{Field: "GravityScale", Original: original, Clamped: fmt.Sprintf("%.2f", clamped.GravityScale)})
+	}
+
+	if clamped.SpellFrequency < 0 || clamped.SpellFrequency > 1 {
+		original := fmt.Sprintf("%.2f", clamped.SpellFrequency)
+		clamped.SpellFrequency = clampFloat(clamped.SpellFrequency, 0, 1)
+		issues = append(issues, settingsIssue{Field: "SpellFrequency", Original: original, Clamped: fmt.Sprintf("%.2f", clamped.SpellFrequency)})
+	}
+
+	if !containsInt(validAILevels, clamped.AILevel) {
+		original := fmt.Sprintf("%d", clamped.AILevel)
+		clamped.AILevel = nearestInt(validAILevels, clamped.AILevel)
+		issues = append(issues, settingsIssue{Field: "AILevel", Original: original, Clamped: fmt.Sprintf("%d", clamped.AILevel)})
+	}
+
+	if !containsGameMode(validGameModes, clamped.DefaultGameMode) {
+		original := string(clamped.DefaultGameMode)
+		clamped.DefaultGameMode = GameModeRace
+		issues = append(issues, settingsIssue{Field: "DefaultGameMode", Original: original, Clamped: string(clamped.DefaultGameMode)})
+	}
+
+	return clamped, issues
+}
+
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func containsInt(values []int, v int) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsGameMode(values []GameMode, v GameMode) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestInt returns whichever entry of values is numerically closest to v,
+// used to clamp AILevel onto the nearest valid level instead of always
+// defaulting it.
+func nearestInt(values []int, v int) int {
+	best := values[0]
+	for _, candidate := range values[1:] {
+		if abs(candidate-v) < abs(best-v) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// decodeGameSettings unmarshals data into a generic map, migrates it up to
+// currentSettingsSchemaVersion, decodes the result into a GameSettings, and
+// validates/clamps it. It never fails just because of out-of-range values -
+// the caller decides whether to accept the clamped result via issues.
+func decodeGameSettings(data []byte) (GameSettings, []settingsIssue, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return GameSettings{}, nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+
+	migrated, err := migrateSettingsMap(raw)
+	if err != nil {
+		return GameSettings{}, nil, err
+	}
+
+	reencoded, err := json.Marshal(migrated)
+	if err != nil {
+		return GameSettings{}, nil, fmt.Errorf("failed to re-encode migrated settings: %w", err)
+	}
+
+	var settings GameSettings
+	if err := json.Unmarshal(reencoded, &settings); err != nil {
+		return GameSettings{}, nil, fmt.Errorf("failed to decode migrated settings: %w", err)
+	}
+
+	clamped, issues := validateAndClampSettings(settings)
+	return clamped, issues, nil
+}
+
+// formatSettingsIssues renders the issues returned by validateAndClampSettings
+// as a multi-line message suitable for the "Settings out of range" dialog.
+func formatSettingsIssues(issues []settingsIssue) string {
+	lines := make([]string, 0, len(issues)+1)
+	lines = append(lines, "Some settings were out of range and have been adjusted:")
+	for _, issue := range issues {
+		lines = append(lines, fmt.Sprintf("- %s: %s -> %s", issue.Field, issue.Original, issue.Clamped))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// gameSettingsJSONSchema builds a JSON Schema (draft-07 style) document
+// describing GameSettings, so external tools (level packs, a future web
+// config UI) can validate a settings file without linking this package.
+func gameSettingsJSONSchema() map[string]interface{} {
+	modes := make([]string, len(validGameModes))
+	for i, m := range validGameModes {
+		modes[i] = string(m)
+	}
+	sort.Strings(modes)
+
+	return map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title":   "GameSettings",
+		"type":    "object",
+		"required": []string{
+			"schema_version", "default_game_mode", "gravity_scale", "spell_frequency", "ai_enabled", "ai_level",
+		},
+		"properties": map[string]interface{}{
+			"schema_version":    map[string]interface{}{"type": "integer", "const": currentSettingsSchemaVersion},
+			"default_game_mode": map[string]interface{}{"type": "string", "enum": modes},
+			"gravity_scale":     map[string]interface{}{"type": "number", "minimum": minGravityScale, "maximum": maxGravityScale},
+			"spell_frequency":   map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+			"ai_enabled":        map[string]interface{}{"type": "boolean"},
+			"ai_level":          map[string]interface{}{"type": "integer", "enum": validAILevels},
+		},
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tetris-towers/dev-tools/tetromino"
+)
+
+/**
+ * SimRunner - headless-движок для детерминированных AI-vs-AI матчей.
+ * Шагает ту же GameState/Block/Tetromino модель, что и GameAnalyzer, без
+ * зависимости от Fyne, и пишет по одному снапшоту на тик в JSONL-трейс,
+ * который затем читает GameAnalyzer.loadGameData.
+ */
+
+// nextQueueSize - сколько предстоящих фигур бот держит в Player.NextTetrominos.
+const nextQueueSize = 3
+
+// SimRunner прогоняет один детерминированный матч бот-против-бота.
+type SimRunner struct {
+	settings GameSettings
+	level    *Level
+	rng      *rand.Rand
+	bags     map[string]*tetromino.Bag // по одному bag-7 рандомайзеру на игрока
+}
+
+// NewSimRunner создаёт раннер с собственным RNG, засеянным seed - одинаковый
+// seed всегда даёт одинаковый трейс.
+func NewSimRunner(settings GameSettings, level *Level, seed int64) *SimRunner {
+	return &SimRunner{
+		settings: settings,
+		level:    level,
+		rng:      rand.New(rand.NewSource(seed)),
+		bags: map[string]*tetromino.Bag{
+			"1": tetromino.NewBag(seed),
+			"2": tetromino.NewBag(seed + 1),
+		},
+	}
+}
+
+// initialState строит стартовое состояние матча для двух игроков-ботов.
+func (sr *SimRunner) initialState() GameState {
+	gameMode := GameModeRace
+	if sr.level != nil {
+		gameMode = sr.level.GameMode
+	}
+
+	players := map[string]Player{
+		"1": {ID: 1, Name: "Bot 1", Health: 100, NextTetrominos: sr.fillNextQueue("1", nil)},
+		"2": {ID: 2, Name: "Bot 2", Health: 100, NextTetrominos: sr.fillNextQueue("2", nil)},
+	}
+
+	return GameState{
+		Players:     players,
+		GameMode:    gameMode,
+		CurrentTurn: 0,
+		GameStatus:  "running",
+		Timer:       0,
+	}
+}
+
+// fillNextQueue tops queue back up to nextQueueSize pieces drawn from the
+// player's bag-7 randomizer, preserving whatever pieces are already queued.
+func (sr *SimRunner) fillNextQueue(playerID string, queue []Tetromino) []Tetromino {
+	bag := sr.bags[playerID]
+	for len(queue) < nextQueueSize {
+		queue = append(queue, Tetromino{Type: bag.Next()})
+	}
+	return queue
+}
+
+// stepTick продвигает состояние на один тик: каждый бот кладёт блок согласно
+// своему AILevel (чем выше уровень, тем точнее целится в центр башни) и
+// получает очки, а с вероятностью SpellFrequency получает случайное заклинание.
+func (sr *SimRunner) stepTick(state *GameState, tick int) {
+	state.Timer += 1.0 / 60.0
+
+	// Игроки обходятся по возрастанию ID, а не в случайном порядке обхода
+	// map, чтобы общий sr.rng при одном seed выдавал им одни и те же числа.
+	ids := make([]string, 0, len(state.Players))
+	for idStr := range state.Players {
+		ids = append(ids, idStr)
+	}
+	sort.Strings(ids)
+
+	for _, idStr := range ids {
+		player := state.Players[idStr]
+		aim := 5.0 // центр игрового поля по X
+		jitter := 2.0 / float64(sr.settings.AILevel+1)
+		x := aim + (sr.rng.Float64()*2-1)*jitter
+
+		// Текущая фигура бота - голова очереди next-фигур, пополняемой из bag-7.
+		current := player.NextTetrominos[0]
+		player.CurrentTetromino = &current
+		player.NextTetrominos = sr.fillNextQueue(idStr, player.NextTetrominos[1:])
+
+		for _, cell := range tetromino.Cells(current.Type, 0) {
+			block := Block{
+				ID:          len(player.TowerBlocks),
+				X:           x + float64(cell.X),
+				Y:           fieldGroundY - float64(len(player.TowerBlocks)+1) - float64(cell.Y),
+				Width:       1,
+				Height:      1,
+				Color:       "#3366CC",
+				Density:     1.0,
+				Friction:    0.3,
+				Restitution: 0.1,
+				IsStatic:    true,
+			}
+			player.TowerBlocks = append(player.TowerBlocks, block)
+		}
+		player.Score += 10 * sr.settings.AILevel
+
+		if sr.settings.SpellFrequency > 0 && sr.rng.Float64() < sr.settings.SpellFrequency {
+			pool := []SpellType{SpellReinforce, SpellStabilize, SpellEarthquake, SpellWind, SpellAccelerate}
+			player.Spells = append(player.Spells, pool[sr.rng.Intn(len(pool))])
+		}
+
+		state.Players[idStr] = player
+	}
+
+	state.CurrentTurn = tick
+}
+
+// winConditionMet оценивает текстовое условие победы уровня, например
+// "height >= 15", против текущей высоты самой высокой башни.
+// Поддерживается единственная переменная "height" и операторы >=, >, <=, <, ==.
+func winConditionMet(condition string, state GameState) bool {
+	fields := strings.Fields(condition)
+	if len(fields) != 3 || fields[0] != "height" {
+		return false
+	}
+
+	threshold, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return false
+	}
+
+	maxHeight := 0.0
+	for _, player := range state.Players {
+		for _, block := range player.TowerBlocks {
+			if h := fieldGroundY - block.Y; h > maxHeight {
+				maxHeight = h
+			}
+		}
+	}
+
+	switch fields[1] {
+	case ">=":
+		return maxHeight >= threshold
+	case ">":
+		return maxHeight > threshold
+	case "<=":
+		return maxHeight <= threshold
+	case "<":
+		return maxHeight < threshold
+	case "==":
+		return maxHeight == threshold
+	default:
+		return false
+	}
+}
+
+// RunTrace гоняет матч до maxTicks тиков (или до выполнения условия победы),
+// записывая один GameState в JSON на строку в w. Возвращает финальное
+// состояние, чтобы вызывающий код мог напечатать сводку по прогону.
+func (sr *SimRunner) RunTrace(w io.Writer, maxTicks int) (GameState, error) {
+	state := sr.initialState()
+	enc := json.NewEncoder(w)
+
+	for tick := 0; tick < maxTicks; tick++ {
+		sr.stepTick(&state, tick)
+
+		if winConditionMet(sr.winCondition(), state) {
+			state.GameStatus = "finished"
+		}
+
+		if err := enc.Encode(state); err != nil {
+			return state, fmt.Errorf("failed to write trace snapshot: %w", err)
+		}
+
+		if state.GameStatus == "finished" {
+			break
+		}
+	}
+
+	return state, nil
+}
+
+// winCondition возвращает условие победы уровня, либо разумное значение по
+// умолчанию, если уровень не задан (например, при запуске без -level).
+func (sr *SimRunner) winCondition() string {
+	if sr.level != nil && sr.level.WinCondition != "" {
+		return sr.level.WinCondition
+	}
+	return "height >= 15"
+}
+
+// RunBatch прогоняет runs независимых матчей (seed = baseSeed+i для каждого)
+// и пишет каждый результат в собственный JSONL-файл через writeTrace.
+// Возвращает финальные состояния всех прогонов в порядке запуска.
+func RunBatch(settings GameSettings, level *Level, baseSeed int64, runs int, writeTrace func(runIndex int) (io.WriteCloser, error), maxTicksPerRun int) ([]GameState, error) {
+	results := make([]GameState, 0, runs)
+
+	for i := 0; i < runs; i++ {
+		runner := NewSimRunner(settings, level, baseSeed+int64(i))
+
+		out, err := writeTrace(i)
+		if err != nil {
+			return results, fmt.Errorf("run %d: failed to open trace output: %w", i, err)
+		}
+
+		final, err := runner.RunTrace(out, maxTicksPerRun)
+		closeErr := out.Close()
+		if err != nil {
+			return results, fmt.Errorf("run %d: simulation failed: %w", i, err)
+		}
+		if closeErr != nil {
+			return results, fmt.Errorf("run %d: failed to close trace output: %w", i, closeErr)
+		}
+
+		results = append(results, final)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"time"
+)
+
+/**
+ * spells - субсистема эффектов заклинаний. SpellType раньше был просто
+ * строковым перечислением без поведения; здесь каждому заклинанию
+ * соответствует реализация Apply(state, caster)/Duration(), а магнитуды
+ * (амплитуда землетрясения, сила ветра, порог массы для левитации и т.д.)
+ * тянутся из JSON-файла правил, который можно редактировать без
+ * перекомпиляции - в духе data-driven trigger/map систем вроде d2df.
+ */
+
+// SpellEffect - один зарегистрированный эффект заклинания.
+type SpellEffect interface {
+	// Apply мутирует состояние игры, применяя эффект от имени игрока caster.
+	Apply(state *GameState, caster int) error
+	// Duration - сколько эффект должен оставаться активным после применения.
+	Duration() time.Duration
+}
+
+// SpellRule - настраиваемые параметры одного заклинания, загружаемые из
+// JSON-файла правил; переопределяет zero-value значения по умолчанию.
+type SpellRule struct {
+	Magnitude       float64 `json:"magnitude"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// SpellRules - правила для всех заклинаний, ключ - SpellType.
+type SpellRules map[SpellType]SpellRule
+
+// defaultSpellRules - встроенные значения по умолчанию, используются для
+// любого заклинания, отсутствующего в загруженном файле правил.
+func defaultSpellRules() SpellRules {
+	return SpellRules{
+		SpellReinforce:  {Magnitude: 0.5, DurationSeconds: 10},
+		SpellStabilize:  {Magnitude: 1.0, DurationSeconds: 10},
+		SpellEnlarge:    {Magnitude: 1.5, DurationSeconds: 8},
+		SpellShrink:     {Magnitude: 0.5, DurationSeconds: 8},
+		SpellLevitate:   {Magnitude: 2.0, DurationSeconds: 5},
+		SpellEarthquake: {Magnitude: 0.3, DurationSeconds: 3},
+		SpellWind:       {Magnitude: 1.0, DurationSeconds: 4},
+		SpellSlippery:   {Magnitude: 0.05, DurationSeconds: 8},
+		SpellConfusion:  {Magnitude: 1.0, DurationSeconds: 6},
+		SpellAccelerate: {Magnitude: 2.0, DurationSeconds: 5},
+	}
+}
+
+// LoadSpellRules читает JSON-файл правил (SpellType -> SpellRule) и
+// накладывает его поверх встроенных значений по умолчанию, так что файл
+// правил может переопределять только то, что нужно дизайнеру.
+func LoadSpellRules(path string) (SpellRules, error) {
+	rules := defaultSpellRules()
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spell rules: %w", err)
+	}
+
+	var overrides SpellRules
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse spell rules: %w", err)
+	}
+	for spell, rule := range overrides {
+		rules[spell] = rule
+	}
+
+	return rules, nil
+}
+
+// NewSpellEffect конструирует эффект для spellType, сконфигурированный по
+// rules. Возвращает ошибку для незарегистрированного типа заклинания.
+func NewSpellEffect(spellType SpellType, rules SpellRules) (SpellEffect, error) {
+	rule, ok := rules[spellType]
+	if !ok {
+		rule = defaultSpellRules()[spellType]
+	}
+	duration := time.Duration(rule.DurationSeconds * float64(time.Second))
+
+	switch spellType {
+	case SpellReinforce:
+		return reinforceSpell{magnitude: rule.Magnitude, duration: duration}, nil
+	case SpellStabilize:
+		return stabilizeSpell{magnitude: rule.Magnitude, duration: duration}, nil
+	case SpellEnlarge:
+		return scaleSpell{factor: rule.Magnitude, duration: duration}, nil
+	case SpellShrink:
+		return scaleSpell{factor: rule.Magnitude, duration: duration}, nil
+	case SpellLevitate:
+		return levitateSpell{massThreshold: rule.Magnitude, duration: duration}, nil
+	case SpellEarthquake:
+		return earthquakeSpell{amplitude: rule.Magnitude, duration: duration}, nil
+	case SpellWind:
+		return windSpell{force: rule.Magnitude, duration: duration}, nil
+	case SpellSlippery:
+		return slipperySpell{frictionDelta: rule.Magnitude, duration: duration}, nil
+	case SpellConfusion:
+		return confusionSpell{duration: duration}, nil
+	case SpellAccelerate:
+		return accelerateSpell{factor: rule.Magnitude, duration: duration}, nil
+	default:
+		return nil, fmt.Errorf("unknown spell type %q", spellType)
+	}
+}
+
+// ApplySpell - точка входа для SimRunner/SettingsEditor: строит эффект по
+// правилам и тут же применяет его к state от имени caster.
+func ApplySpell(spellType SpellType, rules SpellRules, state *GameState, caster int) (SpellEffect, error) {
+	effect, err := NewSpellEffect(spellType, rules)
+	if err != nil {
+		return nil, err
+	}
+	if err := effect.Apply(state, caster); err != nil {
+		return nil, fmt.Errorf("failed to apply %s: %w", spellType, err)
+	}
+	return effect, nil
+}
+
+// opponentTowers возвращает ключи state.Players всех игроков, кроме caster - большинство
+// тёмных заклинаний бьют по противнику, а не по собственной башне.
+func opponentTowers(state *GameState, caster int) []string {
+	var ids []string
+	for idStr, player := range state.Players {
+		if player.ID != caster {
+			ids = append(ids, idStr)
+		}
+	}
+	return ids
+}
+
+// reinforceSpell увеличивает friction (не выше 1.0) всех блоков башни
+// кастера, делая их менее склонными проскальзывать.
+type reinforceSpell struct {
+	magnitude float64
+	duration  time.Duration
+}
+
+func (s reinforceSpell) Duration() time.Duration { return s.duration }
+
+func (s reinforceSpell) Apply(state *GameState, caster int) error {
+	for idStr, player := range state.Players {
+		if player.ID != caster {
+			continue
+		}
+		for i := range player.TowerBlocks {
+			player.TowerBlocks[i].Friction = math.Min(1.0, player.TowerBlocks[i].Friction+s.magnitude)
+		}
+		state.Players[idStr] = player
+	}
+	return nil
+}
+
+// stabilizeSpell принудительно центрирует башню кастера над центром поля,
+// вручную исправляя X-координаты блоков (эквивалент игрового "анти-earthquake").
+type stabilizeSpell struct {
+	magnitude float64
+	duration  time.Duration
+}
+
+func (s stabilizeSpell) Duration() time.Duration { return s.duration }
+
+func (s stabilizeSpell) Apply(state *GameState, caster int) error {
+	const fieldCenterX = 5.0
+	for idStr, player := range state.Players {
+		if player.ID != caster {
+			continue
+		}
+		result := calculateTowerStability(player.TowerBlocks)
+		if result.Score >= 1.0 {
+			continue
+		}
+		for i := range player.TowerBlocks {
+			drift := fieldCenterX - player.TowerBlocks[i].X
+			player.TowerBlocks[i].X += drift * s.magnitude
+		}
+		state.Players[idStr] = player
+	}
+	return nil
+}
+
+// scaleSpell реализует и ENLARGE, и SHRINK - growth factor > 1 увеличивает
+// блоки, < 1 уменьшает. Применяется к последнему блоку башни кастера.
+type scaleSpell struct {
+	factor   float64
+	duration time.Duration
+}
+
+func (s scaleSpell) Duration() time.Duration { return s.duration }
+
+func (s scaleSpell) Apply(state *GameState, caster int) error {
+	for idStr, player := range state.Players {
+		if player.ID != caster || len(player.TowerBlocks) == 0 {
+			continue
+		}
+		last := len(player.TowerBlocks) - 1
+		player.TowerBlocks[last].Width *= s.factor
+		player.TowerBlocks[last].Height *= s.factor
+		state.Players[idStr] = player
+	}
+	return nil
+}
+
+// levitateSpell снимает IsStatic с блоков, чья масса (площадь * плотность)
+// не больше massThreshold, так чтобы физика игры (вне зоны ответственности
+// DevTools) подняла их вверх. Плотность блоков не меняется.
+type levitateSpell struct {
+	massThreshold float64
+	duration      time.Duration
+}
+
+func (s levitateSpell) Duration() time.Duration { return s.duration }
+
+func (s levitateSpell) Apply(state *GameState, caster int) error {
+	for idStr, player := range state.Players {
+		if player.ID != caster {
+			continue
+		}
+		for i, block := range player.TowerBlocks {
+			if block.Width*block.Height*block.Density <= s.massThreshold {
+				player.TowerBlocks[i].IsStatic = false
+			}
+		}
+		state.Players[idStr] = player
+	}
+	return nil
+}
+
+// earthquakeSpell - тёмная магия: случайно смещает блоки башни противника по
+// X на величину до amplitude, повышая шанс, что башня выйдет за опору.
+type earthquakeSpell struct {
+	amplitude float64
+	duration  time.Duration
+}
+
+func (s earthquakeSpell) Duration() time.Duration { return s.duration }
+
+func (s earthquakeSpell) Apply(state *GameState, caster int) error {
+	for _, idStr := range opponentTowers(state, caster) {
+		player := state.Players[idStr]
+		for i := range player.TowerBlocks {
+			shift := s.amplitude * shakeOffset(i)
+			player.TowerBlocks[i].X += shift
+		}
+		state.Players[idStr] = player
+	}
+	return nil
+}
+
+// shakeOffset - детерминированное псевдослучайное смещение в [-1, 1] по
+// индексу блока, чтобы earthquake был воспроизводим без отдельного RNG.
+func shakeOffset(i int) float64 {
+	return math.Sin(float64(i) * 2.4)
+}
+
+// windSpell толкает все незакреплённые блоки противника по X на force,
+// имитируя горизонтальный порыв ветра.
+type windSpell struct {
+	force    float64
+	duration time.Duration
+}
+
+func (s windSpell) Duration() time.Duration { return s.duration }
+
+func (s windSpell) Apply(state *GameState, caster int) error {
+	for _, idStr := range opponentTowers(state, caster) {
+		player := state.Players[idStr]
+		for i := range player.TowerBlocks {
+			if player.TowerBlocks[i].IsStatic {
+				continue
+			}
+			player.TowerBlocks[i].X += s.force
+		}
+		state.Players[idStr] = player
+	}
+	return nil
+}
+
+// slipperySpell снижает friction блоков противника на frictionDelta,
+// приближая их к порогу проскальзывания (см. calculateTowerStability.SlipRisk).
+type slipperySpell struct {
+	frictionDelta float64
+	duration      time.Duration
+}
+
+func (s slipperySpell) Duration() time.Duration { return s.duration }
+
+func (s slipperySpell) Apply(state *GameState, caster int) error {
+	for _, idStr := range opponentTowers(state, caster) {
+		player := state.Players[idStr]
+		for i := range player.TowerBlocks {
+			player.TowerBlocks[i].Friction = math.Max(0, player.TowerBlocks[i].Friction-s.frictionDelta)
+		}
+		state.Players[idStr] = player
+	}
+	return nil
+}
+
+// confusionSpell не мутирует геометрию - это чисто управленческий эффект
+// (инверсия ввода у противника), поэтому здесь он лишь добавляет
+// SpellConfusion в Spells противника для потребления игровым клиентом,
+// который реализует ввод.
+type confusionSpell struct {
+	duration time.Duration
+}
+
+func (s confusionSpell) Duration() time.Duration { return s.duration }
+
+func (s confusionSpell) Apply(state *GameState, caster int) error {
+	for _, idStr := range opponentTowers(state, caster) {
+		player := state.Players[idStr]
+		player.Spells = append(player.Spells, SpellConfusion)
+		state.Players[idStr] = player
+	}
+	return nil
+}
+
+// accelerateSpell ускоряет падение текущей фигуры противника, приближая её
+// текущий Y к полю в factor раз быстрее - здесь приближенно как прыжок вниз.
+type accelerateSpell struct {
+	factor   float64
+	duration time.Duration
+}
+
+func (s accelerateSpell) Duration() time.Duration { return s.duration }
+
+func (s accelerateSpell) Apply(state *GameState, caster int) error {
+	for _, idStr := range opponentTowers(state, caster) {
+		player := state.Players[idStr]
+		if player.CurrentTetromino != nil {
+			player.CurrentTetromino.Y += s.factor
+		}
+		state.Players[idStr] = player
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Package tetromino implements the Guideline-standard piece logic that every
+// serious Tetris clone needs: the bag-of-7 randomizer, SRS rotation states
+// with the JLSTZ/I wall-kick offset tables, and piece-to-cell expansion so a
+// single Type+rotation can be stamped onto a grid as four concrete cells.
+// This used to live as a bare string enum inline in the DevTools GUI code;
+// it's pulled out here so both the level editor and the headless simulation
+// runner can share the same rules.
+package tetromino
+
+import (
+	"image"
+	"math/rand"
+)
+
+// Type identifies one of the seven standard tetromino shapes.
+type Type string
+
+const (
+	I Type = "I"
+	J Type = "J"
+	L Type = "L"
+	O Type = "O"
+	S Type = "S"
+	T Type = "T"
+	Z Type = "Z"
+)
+
+// All is every piece type, in the canonical order used to seed a fresh bag.
+var All = []Type{I, J, L, O, S, T, Z}
+
+// Bag draws pieces using the standard "bag of 7": all seven pieces are
+// shuffled and handed out before any piece repeats, which is what every
+// Guideline-compliant Tetris (and the clones referenced in the request -
+// twintris, tvintris) implements instead of pure uniform random choice.
+type Bag struct {
+	rng   *rand.Rand
+	queue []Type
+}
+
+// NewBag creates a bag-of-7 randomizer seeded deterministically - the same
+// seed always produces the same piece sequence, which is what SimRunner
+// needs for reproducible traces.
+func NewBag(seed int64) *Bag {
+	return &Bag{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Next pops the next piece, refilling and reshuffling the bag when empty.
+func (b *Bag) Next() Type {
+	if len(b.queue) == 0 {
+		b.refill()
+	}
+	piece := b.queue[0]
+	b.queue = b.queue[1:]
+	return piece
+}
+
+func (b *Bag) refill() {
+	b.queue = append(b.queue, All...)
+	b.rng.Shuffle(len(b.queue), func(i, j int) {
+		b.queue[i], b.queue[j] = b.queue[j], b.queue[i]
+	})
+}
+
+// NextFromBag is a stateless convenience for callers that just need a single
+// piece (e.g. populating one Player.NextTetrominos slot) and don't want to
+// carry a *Bag around: it spins up a fresh bag from seed and returns its
+// first piece. Callers that need a whole sequence should use NewBag instead,
+// since calling this repeatedly with the same seed always returns the same piece.
+func NextFromBag(seed int64) Type {
+	return NewBag(seed).Next()
+}
+
+// rotationState is one of the four SRS orientations: 0=spawn, 1=R (clockwise
+// from spawn), 2=180, 3=L (counter-clockwise from spawn).
+type rotationState int
+
+// kickKey identifies a rotation transition, e.g. "0->R" or "R->2".
+type kickKey struct {
+	from, to int
+}
+
+// jlstzKicks is the standard SRS wall-kick offset table shared by J, L, S, T
+// and Z pieces: five (dx, dy) candidate offsets tried in order for each
+// rotation transition, in tile units with +y downward (Fyne/image convention).
+var jlstzKicks = map[kickKey][]image.Point{
+	{0, 1}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{1, 0}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	{1, 2}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	{2, 1}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{2, 3}: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{3, 2}: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{3, 0}: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{0, 3}: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+}
+
+// iKicks is the wider offset table used only by the I piece.
+var iKicks = map[kickKey][]image.Point{
+	{0, 1}: {{0, 0}, {-2, 0}, {1, 0}, {-2, 1}, {1, -2}},
+	{1, 0}: {{0, 0}, {2, 0}, {-1, 0}, {2, -1}, {-1, 2}},
+	{1, 2}: {{0, 0}, {-1, 0}, {2, 0}, {-1, -2}, {2, 1}},
+	{2, 1}: {{0, 0}, {1, 0}, {-2, 0}, {1, 2}, {-2, -1}},
+	{2, 3}: {{0, 0}, {2, 0}, {-1, 0}, {2, -1}, {-1, 2}},
+	{3, 2}: {{0, 0}, {-2, 0}, {1, 0}, {-2, 1}, {1, -2}},
+	{3, 0}: {{0, 0}, {1, 0}, {-2, 0}, {1, 2}, {-2, -1}},
+	{0, 3}: {{0, 0}, {-1, 0}, {2, 0}, {-1, -2}, {2, 1}},
+}
+
+// Kick returns the ordered list of candidate (dx, dy) offsets to try when
+// rotating t from rotation state `from` to `to` (both in 0..3, SRS spawn/R/2/L
+// convention). The caller should try each offset in order and use the first
+// one where the rotated piece doesn't collide. O doesn't kick (it doesn't
+// rotate visually), so it returns a single zero offset.
+func Kick(t Type, from, to int) []image.Point {
+	if t == O {
+		return []image.Point{{0, 0}}
+	}
+
+	key := kickKey{from, to}
+	table := jlstzKicks
+	if t == I {
+		table = iKicks
+	}
+
+	if offsets, ok := table[key]; ok {
+		return append([]image.Point(nil), offsets...)
+	}
+	return []image.Point{{0, 0}}
+}
+
+// cellShapes maps each piece type and rotation state (0..3) to the four
+// cells it occupies in a 4x4 bounding box (standard SRS orientations, +y
+// downward). Index 0 is always the spawn orientation.
+var cellShapes = map[Type][4][]image.Point{
+	I: {
+		{{0, 1}, {1, 1}, {2, 1}, {3, 1}},
+		{{2, 0}, {2, 1}, {2, 2}, {2, 3}},
+		{{0, 2}, {1, 2}, {2, 2}, {3, 2}},
+		{{1, 0}, {1, 1}, {1, 2}, {1, 3}},
+	},
+	O: {
+		{{1, 0}, {2, 0}, {1, 1}, {2, 1}},
+		{{1, 0}, {2, 0}, {1, 1}, {2, 1}},
+		{{1, 0}, {2, 0}, {1, 1}, {2, 1}},
+		{{1, 0}, {2, 0}, {1, 1}, {2, 1}},
+	},
+	T: {
+		{{1, 0}, {0, 1}, {1, 1}, {2, 1}},
+		{{1, 0}, {1, 1}, {2, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {1, 2}},
+		{{1, 0}, {0, 1}, {1, 1}, {1, 2}},
+	},
+	S: {
+		{{1, 0}, {2, 0}, {0, 1}, {1, 1}},
+		{{1, 0}, {1, 1}, {2, 1}, {2, 2}},
+		{{1, 1}, {2, 1}, {0, 2}, {1, 2}},
+		{{0, 0}, {0, 1}, {1, 1}, {1, 2}},
+	},
+	Z: {
+		{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+		{{2, 0}, {1, 1}, {2, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {1, 2}, {2, 2}},
+		{{1, 0}, {0, 1}, {1, 1}, {0, 2}},
+	},
+	J: {
+		{{0, 0}, {0, 1}, {1, 1}, {2, 1}},
+		{{1, 0}, {2, 0}, {1, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {2, 2}},
+		{{1, 0}, {1, 1}, {0, 2}, {1, 2}},
+	},
+	L: {
+		{{2, 0}, {0, 1}, {1, 1}, {2, 1}},
+		{{1, 0}, {1, 1}, {1, 2}, {2, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {0, 2}},
+		{{0, 0}, {1, 0}, {1, 1}, {1, 2}},
+	},
+}
+
+// Cells returns the four cells (in a 4x4 bounding box, +y downward) that t
+// occupies at the given SRS rotation state (0..3). Lets LevelEditor.addTetromino
+// stamp a whole tetromino instead of a single 1x1 cell.
+func Cells(t Type, rotation int) []image.Point {
+	shapes, ok := cellShapes[t]
+	if !ok {
+		return nil
+	}
+	cells := shapes[((rotation%4)+4)%4]
+	return append([]image.Point(nil), cells...)
+}
@@ -0,0 +1,153 @@
+package tetromino
+
+import (
+	"image"
+	"testing"
+)
+
+// TestBagExhaustsBeforeRepeating checks the defining property of a bag-of-7
+// randomizer: every piece appears exactly once before any piece repeats.
+func TestBagExhaustsBeforeRepeating(t *testing.T) {
+	bag := NewBag(1)
+
+	seen := make(map[Type]int, len(All))
+	for i := 0; i < len(All); i++ {
+		seen[bag.Next()]++
+	}
+	for _, piece := range All {
+		if seen[piece] != 1 {
+			t.Errorf("piece %s appeared %d times in the first 7 draws, want exactly 1", piece, seen[piece])
+		}
+	}
+
+	// The next 7 draws are a fresh shuffle of the same 7 pieces, not a
+	// continuation that could repeat one early.
+	seen = make(map[Type]int, len(All))
+	for i := 0; i < len(All); i++ {
+		seen[bag.Next()]++
+	}
+	for _, piece := range All {
+		if seen[piece] != 1 {
+			t.Errorf("piece %s appeared %d times in the second 7 draws, want exactly 1", piece, seen[piece])
+		}
+	}
+}
+
+// TestBagDeterministic checks that NewBag's seed fully determines the piece
+// sequence, which is what SimRunner relies on for reproducible traces.
+func TestBagDeterministic(t *testing.T) {
+	first := NewBag(42)
+	second := NewBag(42)
+
+	for i := 0; i < len(All)*3; i++ {
+		a, b := first.Next(), second.Next()
+		if a != b {
+			t.Fatalf("draw %d: bags seeded with 42 diverged: got %s and %s", i, a, b)
+		}
+	}
+}
+
+// TestNextFromBagMatchesFreshBag checks NextFromBag against the documented
+// behavior: it's equivalent to NewBag(seed).Next(), not an independent draw.
+func TestNextFromBagMatchesFreshBag(t *testing.T) {
+	seed := int64(7)
+	want := NewBag(seed).Next()
+	got := NextFromBag(seed)
+	if got != want {
+		t.Errorf("NextFromBag(%d) = %s, want %s (NewBag(%d).Next())", seed, got, want, seed)
+	}
+}
+
+func TestKickOPieceNeverKicks(t *testing.T) {
+	offsets := Kick(O, 0, 1)
+	if len(offsets) != 1 || offsets[0] != (image.Point{0, 0}) {
+		t.Errorf("Kick(O, 0, 1) = %v, want [{0 0}]", offsets)
+	}
+}
+
+func TestKickKnownTransitions(t *testing.T) {
+	cases := []struct {
+		piece    Type
+		from, to int
+		want     []image.Point
+	}{
+		{T, 0, 1, []image.Point{{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}}},
+		{I, 0, 1, []image.Point{{0, 0}, {-2, 0}, {1, 0}, {-2, 1}, {1, -2}}},
+	}
+
+	for _, c := range cases {
+		got := Kick(c.piece, c.from, c.to)
+		if len(got) != len(c.want) {
+			t.Fatalf("Kick(%s, %d, %d) = %v, want %v", c.piece, c.from, c.to, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("Kick(%s, %d, %d)[%d] = %v, want %v", c.piece, c.from, c.to, i, got[i], c.want[i])
+			}
+		}
+	}
+}
+
+// TestKickUnknownTransitionFallsBackToZero checks that a rotation transition
+// outside the SRS table (e.g. a direct 0->2) falls back to a single zero
+// offset rather than panicking on a missing map entry.
+func TestKickUnknownTransitionFallsBackToZero(t *testing.T) {
+	offsets := Kick(T, 0, 2)
+	if len(offsets) != 1 || offsets[0] != (image.Point{0, 0}) {
+		t.Errorf("Kick(T, 0, 2) = %v, want [{0 0}]", offsets)
+	}
+}
+
+func TestCellsSpawnOrientation(t *testing.T) {
+	cases := []struct {
+		piece Type
+		want  []image.Point
+	}{
+		{I, []image.Point{{0, 1}, {1, 1}, {2, 1}, {3, 1}}},
+		{O, []image.Point{{1, 0}, {2, 0}, {1, 1}, {2, 1}}},
+		{T, []image.Point{{1, 0}, {0, 1}, {1, 1}, {2, 1}}},
+	}
+
+	for _, c := range cases {
+		got := Cells(c.piece, 0)
+		if len(got) != len(c.want) {
+			t.Fatalf("Cells(%s, 0) = %v, want %v", c.piece, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("Cells(%s, 0)[%d] = %v, want %v", c.piece, i, got[i], c.want[i])
+			}
+		}
+	}
+}
+
+// TestCellsRotationWraps checks that Cells normalizes rotation into 0..3,
+// both for values beyond 3 and for negative values.
+func TestCellsRotationWraps(t *testing.T) {
+	base := Cells(T, 1)
+
+	if got := Cells(T, 5); !equalPoints(got, base) {
+		t.Errorf("Cells(T, 5) = %v, want %v (same as rotation 1)", got, base)
+	}
+	if got := Cells(T, -3); !equalPoints(got, base) {
+		t.Errorf("Cells(T, -3) = %v, want %v (same as rotation 1)", got, base)
+	}
+}
+
+func TestCellsUnknownTypeReturnsNil(t *testing.T) {
+	if got := Cells(Type("X"), 0); got != nil {
+		t.Errorf("Cells(\"X\", 0) = %v, want nil", got)
+	}
+}
+
+func equalPoints(a, b []image.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}